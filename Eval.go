@@ -2,11 +2,11 @@ package main
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -15,8 +15,6 @@ import (
 	"strings"
 	"syscall"
 	"time"
-
-	"github.com/PuerkitoBio/goquery"
 )
 
 // Config struct pour JSON
@@ -25,14 +23,141 @@ type Config struct {
 	BaseDir     string `json:"base_dir"`
 	OutDir      string `json:"out_dir"`
 	DefaultExt  string `json:"default_ext"`
+
+	// Encodage des fichiers générés (utf-8, utf-8-bom, utf-16le, utf-16be, windows-1252, iso-8859-1)
+	OutputEncoding string `json:"output_encoding"`
+
+	// Intervalle de scrutation du mode tail -f, en millisecondes
+	FollowIntervalMs int `json:"follow_interval_ms"`
+
+	// Nombre de fichiers analysés en parallèle par le choix B (0 = nombre de CPU)
+	Concurrency int `json:"concurrency"`
+
+	// Filtres du choix B
+	Extensions    []string `json:"extensions"`     // extensions acceptées (défaut : default_ext)
+	Include       []string `json:"include"`        // motifs à inclure, "!motif" pour exclure
+	Exclude       []string `json:"exclude"`        // motifs à exclure
+	MaxDepth      int      `json:"max_depth"`      // profondeur maximale (0 = illimitée)
+	MinSize       int64    `json:"min_size"`       // taille minimale en octets (0 = pas de borne)
+	MaxSize       int64    `json:"max_size"`       // taille maximale en octets (0 = pas de borne)
+	IncludeHidden bool     `json:"include_hidden"` // analyser aussi les fichiers et dossiers cachés
+	UseGitignore  bool     `json:"use_gitignore"`  // respecter les fichiers .gitignore / .ignore
+
+	// Suivre les liens symboliques vers des dossiers pendant les parcours
+	FollowSymlinks bool `json:"follow_symlinks"`
+
+	// Format des rapports : text, json, csv ou markdown
+	ReportFormat string `json:"report_format"`
+
+	// Générer aussi un rapport HTML autonome (choix A, B et C)
+	HTMLReport bool `json:"html_report"`
+
+	// Mise en forme de merged.txt (choix B) : {path}, {size}, {mtime} et {lines} sont remplacés
+	MergeHeader      string `json:"merge_header"`       // en-tête avant chaque fichier
+	MergeSeparator   string `json:"merge_separator"`    // séparateur entre deux fichiers
+	MergeLineNumbers bool   `json:"merge_line_numbers"` // préfixer chaque ligne par son numéro
+	MergeMarkers     bool   `json:"merge_markers"`      // marqueurs de provenance, pour le découpage
+
+	// Cache du choix B dans OutDir/scan_cache.json : les fichiers inchangés ne sont pas réanalysés
+	ScanCache     bool `json:"scan_cache"`
+	ScanCacheHash bool `json:"scan_cache_hash"` // vérifier aussi l'empreinte SHA-256
+	Rescan        bool `json:"-"`               // flag -rescan : réanalyser sans reprendre le cache
+
+	// Wikipédia (choix C) : édition et adresse des articles, {lang} est remplacé par l'édition
+	WikiLang     string `json:"wiki_lang"`     // fr, en, de, es... (défaut : fr)
+	WikiBaseURL  string `json:"wiki_base_url"` // défaut : https://{lang}.wikipedia.org/wiki/
+	WikiAPIURL   string `json:"wiki_api_url"`  // défaut : déduite de wiki_base_url (/w/api.php)
+	WikiFetch    string `json:"wiki_fetch"`    // api (défaut, page HTML en secours) ou html
+	WikiHeadings bool   `json:"wiki_headings"` // garder les titres de sections
+	WikiLists    bool   `json:"wiki_lists"`    // garder les éléments de listes
+	WikiCaptions bool   `json:"wiki_captions"` // garder les légendes de tableaux
+
+	// Lot d'articles et exploration des liens (choix C)
+	WikiCrawlDepth       int `json:"wiki_crawl_depth"`       // profondeur des liens suivis (défaut : 1)
+	WikiCrawlMaxPages    int `json:"wiki_crawl_max_pages"`   // pages au maximum (défaut : 20)
+	WikiCrawlConcurrency int `json:"wiki_crawl_concurrency"` // téléchargements simultanés (défaut : 2)
+	WikiCrawlDelayMs     int `json:"wiki_crawl_delay_ms"`    // pause après chaque page (défaut : 500)
+
+	// User-Agent des requêtes HTTP (défaut : fileops/1.0 avec l'adresse du projet)
+	UserAgent string `json:"user_agent"`
+
+	// Analyse d'une page web (choix G) : sélecteur CSS du contenu, vide = détection automatique
+	WebSelector string `json:"web_selector"`
+
+	// Client HTTP (délais en millisecondes, 0 = sans limite)
+	HTTPTimeoutMs        int     `json:"http_timeout_ms"`         // requête complète (défaut : 30000)
+	HTTPConnectTimeoutMs int     `json:"http_connect_timeout_ms"` // connexion et TLS (défaut : 10000)
+	HTTPRetries          int     `json:"http_retries"`            // nouvelles tentatives (429, 5xx, erreur réseau) (défaut : 3)
+	HTTPRetryBaseMs      int     `json:"http_retry_base_ms"`      // première attente, doublée à chaque essai (défaut : 500)
+	HTTPRetryMaxMs       int     `json:"http_retry_max_ms"`       // attente maximale (défaut : 30000)
+	HTTPMaxRedirects     int     `json:"http_max_redirects"`      // redirections suivies (défaut : 10)
+	HTTPRate             float64 `json:"http_rate"`               // requêtes par seconde et par hôte, 0 = sans limite (défaut : 1)
+
+	// Cache HTTP dans OutDir/http_cache
+	HTTPCache       bool `json:"http_cache"`
+	HTTPCacheTTLMin int  `json:"http_cache_ttl_min"` // durée sans revalidation, en minutes (défaut : 60)
+	Offline         bool `json:"offline"`            // n'utiliser que le cache (flag -offline)
 }
 
 func main() {
 	// Flag JSON config
 	configPath := flag.String("config", "config.json", "Chemin vers config JSON")
+	outEncoding := flag.String("encoding", "", "Encodage des fichiers générés (remplace output_encoding)")
+	reportFormat := flag.String("format", "", "Format des rapports : text, json, csv, markdown (remplace report_format)")
+	htmlReport := flag.Bool("html", false, "Générer aussi un rapport HTML (active html_report)")
+	rescan := flag.Bool("rescan", false, "Tout réanalyser au choix B sans reprendre le cache")
+	wikiLang := flag.String("wiki-lang", "", "Édition de Wikipédia : fr, en, de... (remplace wiki_lang)")
+	wikiURL := flag.String("wiki-url", "", "Adresse des articles, {lang} = édition (remplace wiki_base_url)")
+	offline := flag.Bool("offline", false, "Ne rien télécharger, n'utiliser que le cache HTTP (active offline)")
 	flag.Parse()
 
 	cfg := loadConfig(*configPath)
+	if *outEncoding != "" {
+		cfg.OutputEncoding = *outEncoding
+	}
+	cfg.OutputEncoding = checkOutputEncoding(cfg.OutputEncoding)
+	if *reportFormat != "" {
+		cfg.ReportFormat = *reportFormat
+	}
+	cfg.ReportFormat = checkReportFormat(cfg.ReportFormat)
+	if *htmlReport {
+		cfg.HTMLReport = true
+	}
+	cfg.Rescan = *rescan
+	if *wikiLang != "" {
+		cfg.WikiLang = *wikiLang
+	}
+	if *wikiURL != "" {
+		cfg.WikiBaseURL = *wikiURL
+	}
+	cfg.WikiLang, cfg.WikiBaseURL = checkWikiConfig(cfg.WikiLang, cfg.WikiBaseURL)
+	if cfg.WikiFetch != "html" {
+		cfg.WikiFetch = "api"
+	}
+	if cfg.UserAgent == "" {
+		cfg.UserAgent = defaultUserAgent
+	}
+	if cfg.FollowIntervalMs <= 0 {
+		cfg.FollowIntervalMs = 1000
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = runtime.NumCPU()
+	}
+	cfg.WikiCrawlDepth = max(cfg.WikiCrawlDepth, 0)
+	cfg.WikiCrawlMaxPages = max(cfg.WikiCrawlMaxPages, 1)
+	cfg.WikiCrawlConcurrency = max(cfg.WikiCrawlConcurrency, 1)
+	cfg.WikiCrawlDelayMs = max(cfg.WikiCrawlDelayMs, 0)
+	cfg.HTTPTimeoutMs = max(cfg.HTTPTimeoutMs, 0)
+	cfg.HTTPConnectTimeoutMs = max(cfg.HTTPConnectTimeoutMs, 0)
+	cfg.HTTPRetries = max(cfg.HTTPRetries, 0)
+	cfg.HTTPRetryBaseMs = max(cfg.HTTPRetryBaseMs, 0)
+	cfg.HTTPRetryMaxMs = max(cfg.HTTPRetryMaxMs, cfg.HTTPRetryBaseMs)
+	cfg.HTTPMaxRedirects = max(cfg.HTTPMaxRedirects, 0)
+	cfg.HTTPRate = max(cfg.HTTPRate, 0)
+	cfg.HTTPCacheTTLMin = max(cfg.HTTPCacheTTLMin, 0)
+	if *offline {
+		cfg.Offline = true
+	}
 	reader := bufio.NewReader(os.Stdin)
 
 	// Création du dossier out si inexistant
@@ -47,6 +172,9 @@ func main() {
 		fmt.Println("4 - Choix D (ProcessOps)")
 		fmt.Println("5 - Choix E (SecureOps)")
 		fmt.Println("6 - QUITTER")
+		// Les nouveaux choix sont ajoutés après QUITTER, qui garde sa touche
+		fmt.Println("7 - Choix F (Outils dossiers)")
+		fmt.Println("8 - Choix G (Analyse page web)")
 		fmt.Println()
 		fmt.Print("Choix : ")
 
@@ -59,7 +187,7 @@ func main() {
 		case "2":
 			choixB(cfg, reader)
 		case "3":
-			choixWiki(cfg, reader)
+			choixWikiMenu(cfg, reader)
 		case "4":
 			choixProcessOps(reader)
 		case "5":
@@ -67,6 +195,10 @@ func main() {
 		case "6":
 			fmt.Println("Fin du programme.")
 			return
+		case "7":
+			choixOutils(cfg, reader)
+		case "8":
+			choixWeb(cfg, reader)
 		default:
 			fmt.Println("Choix invalide.")
 		}
@@ -82,6 +214,23 @@ func loadConfig(path string) Config {
 		BaseDir:     "data",
 		OutDir:      "out",
 		DefaultExt:  ".txt",
+
+		OutputEncoding:   encUTF8,
+		FollowIntervalMs: 1000,
+
+		WikiCrawlDepth:       1,
+		WikiCrawlMaxPages:    20,
+		WikiCrawlConcurrency: 2,
+		WikiCrawlDelayMs:     500,
+
+		HTTPTimeoutMs:        30000,
+		HTTPConnectTimeoutMs: 10000,
+		HTTPRetries:          3,
+		HTTPRetryBaseMs:      500,
+		HTTPRetryMaxMs:       30000,
+		HTTPMaxRedirects:     10,
+		HTTPRate:             1,
+		HTTPCacheTTLMin:      60,
 	}
 
 	// Lire le fichier config.json
@@ -111,37 +260,58 @@ func askPath(reader *bufio.Reader, def string) string {
 	return input
 }
 
+// Question fermée, "o" pour oui (non par défaut)
+func askYes(reader *bufio.Reader, question string) bool {
+	fmt.Print(question + " (o/N) : ")
+	answer, _ := reader.ReadString('\n')
+	return strings.ToLower(strings.TrimSpace(answer)) == "o"
+}
+
 // Choix A
 func choixA(cfg Config, reader *bufio.Reader) {
 	path := askPath(reader, cfg.DefaultFile)
 
-	info, err := os.Stat(path)
-	if err != nil || info.IsDir() {
+	// Fichier simple, compressé (.gz, .bz2) ou membre d'archive (archive.zip/interne.txt)
+	entry, err := resolveInput(path)
+	if err != nil {
 		fmt.Println("Fichier invalide.")
 		return
 	}
 
+	// Archive entière : on choisit le membre à analyser
+	if isArchive(entry.Path) {
+		entry, err = chooseArchiveMember(reader, entry.Path)
+		if err != nil {
+			fmt.Println("Erreur archive :", err)
+			return
+		}
+		fmt.Println("Membre analysé :", entry.Path)
+	}
+
 	// Afficher les infos du fichier
-	fmt.Println("Taille :", info.Size(), "bytes")
-	fmt.Println("Modifié :", info.ModTime().Format(time.RFC3339))
+	fmt.Println("Taille :", entry.Size, "bytes")
+	fmt.Println("Modifié :", entry.ModTime.Format(time.RFC3339))
 
-	file, err := os.Open(path)
+	// Lecture du fichier décompressé et décodé en UTF-8 (Latin-1, Windows-1252, UTF-16...)
+	data, err := entry.read()
 	if err != nil {
 		fmt.Println("Erreur ouverture fichier.")
 		return
 	}
-	defer file.Close()
+	text, enc := decodeText(data)
+	fmt.Println("Encodage détecté :", enc)
 
 	// Lire les lignes du fichier et les stocker dans un slice
 	var lines []string
-	sc := bufio.NewScanner(file)
+	sc := bufio.NewScanner(strings.NewReader(text))
 	for sc.Scan() {
 		line := strings.TrimSpace(sc.Text())
 		if line != "" {
 			lines = append(lines, line)
 		}
 	}
-	fmt.Println("Nombre de lignes :", len(lines))
+	fmt.Println("Nombre de lignes :", countLines(text))
+	fmt.Println("Lignes non vides :", len(lines))
 
 	// Stats des mots (en ignorant les valeurs numériques)
 	totalWords := 0
@@ -161,6 +331,52 @@ func choixA(cfg Config, reader *bufio.Reader) {
 		fmt.Println("Longueur moyenne :", totalLen/totalWords)
 	}
 
+	// Langue détectée (profils de n-grammes embarqués)
+	lang := detectLanguage(strings.Join(lines, "\n"))
+	fmt.Println("Langue détectée :", lang)
+
+	// Rapport du fichier dans le format choisi (analyse.txt / .json / .csv / .md)
+	os.MkdirAll(cfg.OutDir, os.ModePerm)
+	res := fileResult{Entry: entry, Encoding: enc, Lines: countLines(text), Words: totalWords, WordLen: totalLen, Lang: lang}
+	sum := sha256.Sum256(data)
+	res.SHA256 = hex.EncodeToString(sum[:])
+	if files, err := writeReport(cfg, "analyse", []reportTable{fileRecordsTable([]fileRecord{res.record()})}); err != nil {
+		fmt.Println("Erreur rapport :", err)
+	} else {
+		fmt.Println("Rapport généré :", files[0])
+	}
+
+	// Rapport HTML (mots les plus fréquents en graphique)
+	if cfg.HTMLReport {
+		freq := wordFrequencies(text, lang.Lang)
+		rec := res.record()
+		page := htmlReport{
+			Title: "Analyse de " + entry.Path,
+			Summary: [][2]string{
+				{"Fichier", entry.Path},
+				{"Taille", fmt.Sprint(entry.Size, " octets")},
+				{"Lignes", fmt.Sprint(res.Lines)},
+				{"Lignes non vides", fmt.Sprint(len(lines))},
+				{"Mots", fmt.Sprint(totalWords)},
+				{"Encodage", enc},
+				{"Langue", lang.String()},
+			},
+			Chart: wordChart(topWords(freq, htmlTopWords)),
+			Files: []htmlFile{newHTMLFile(rec, freq)},
+		}
+		if p, err := writeHTMLReport(cfg, "analyse.html", page); err != nil {
+			fmt.Println("Erreur rapport HTML :", err)
+		} else {
+			fmt.Println("Rapport HTML généré :", p)
+		}
+	}
+
+	// Mode log : niveaux, histogramme, filtrage par période et niveau
+	if askYes(reader, "Analyser comme un fichier de log ?") {
+		os.MkdirAll(cfg.OutDir, os.ModePerm)
+		analyzeLogs(cfg, reader, lines)
+	}
+
 	// On rentre le Mot-clé
 	fmt.Print("Mot-clé : ")
 	keyword, _ := reader.ReadString('\n')
@@ -169,10 +385,18 @@ func choixA(cfg Config, reader *bufio.Reader) {
 	// Créer le dossier out si inexistant
 	os.MkdirAll(cfg.OutDir, os.ModePerm)
 
-	// Fichiers de sortie
-	fYes, _ := os.Create(filepath.Join(cfg.OutDir, "filtered.txt"))
+	// Fichiers de sortie (dans l'encodage choisi)
+	fYes, err := createTextOutput(filepath.Join(cfg.OutDir, "filtered.txt"), cfg.OutputEncoding)
+	if err != nil {
+		fmt.Println("Erreur création fichier :", err)
+		return
+	}
 	defer fYes.Close()
-	fNo, _ := os.Create(filepath.Join(cfg.OutDir, "filtered_not.txt"))
+	fNo, err := createTextOutput(filepath.Join(cfg.OutDir, "filtered_not.txt"), cfg.OutputEncoding)
+	if err != nil {
+		fmt.Println("Erreur création fichier :", err)
+		return
+	}
 	defer fNo.Close()
 
 	// Filtrer les lignes et écrire dans les fichiers de sortie
@@ -200,23 +424,56 @@ func choixA(cfg Config, reader *bufio.Reader) {
 	fmt.Println("Lignes contenant le mot-clé :", count)
 	fmt.Println("Fichiers générés dans", cfg.OutDir)
 
-	// Head / Tail
-	fmt.Print("Choix des lignes à garder pour head/tail : ")
-	nStr, _ := reader.ReadString('\n')
-	n, _ := strconv.Atoi(strings.TrimSpace(nStr))
+	// Head / Tail par lignes (par défaut) ou par octets
+	fmt.Print("Head/tail par lignes ou par octets ? (l/o, ENTER = lignes) : ")
+	mode, _ := reader.ReadString('\n')
+	byBytes := strings.ToLower(strings.TrimSpace(mode)) == "o"
 
-	if n > len(lines) {
-		n = len(lines)
+	if byBytes {
+		fmt.Print("Nombre d'octets à garder pour head/tail (ENTER = 1024) : ")
+	} else {
+		fmt.Print("Choix des lignes à garder pour head/tail (ENTER = 10) : ")
+	}
+	nStr, _ := reader.ReadString('\n')
+	def := 10
+	if byBytes {
+		def = 1024
+	}
+	n, err := parseCount(nStr, def)
+	if err != nil {
+		fmt.Println("Erreur head/tail :", err)
+		return
 	}
 
-	head := strings.Join(lines[:n], "\n")
-	tail := strings.Join(lines[len(lines)-n:], "\n")
+	var head, tail string
+	if byBytes {
+		head, tail, err = headTailBytes(text, n)
+	} else {
+		var h, t []string
+		h, t, err = headTailLines(lines, n)
+		head, tail = strings.Join(h, "\n"), strings.Join(t, "\n")
+	}
+	if err != nil {
+		fmt.Println("Erreur head/tail :", err)
+		return
+	}
 
 	// Écrire head et tail dans des fichiers suivants : head.txt et tail.txt
-	os.WriteFile(cfg.OutDir+"/head.txt", []byte(head), 0644)
-	os.WriteFile(cfg.OutDir+"/tail.txt", []byte(tail), 0644)
+	writeTextFile(cfg.OutDir+"/head.txt", head, cfg.OutputEncoding)
+	writeTextFile(cfg.OutDir+"/tail.txt", tail, cfg.OutputEncoding)
 
 	fmt.Println("Fichiers générés dans", cfg.OutDir)
+
+	// Suivi du fichier (tail -f), uniquement pour un fichier non compressé du disque
+	if _, err := os.Stat(entry.Path); err != nil || stripCompressionExt(entry.Path) != entry.Path {
+		return
+	}
+	if askYes(reader, "Suivre le fichier (tail -f) ?") {
+		interval := time.Duration(cfg.FollowIntervalMs) * time.Millisecond
+		if err := followFile(entry.Path, reader, interval); err != nil {
+			fmt.Println("Erreur suivi :", err)
+		}
+	}
 }
 
 // choix B
@@ -224,52 +481,143 @@ func choixB(cfg Config, reader *bufio.Reader) {
 	dir := askPath(reader, cfg.BaseDir)
 	os.MkdirAll(cfg.OutDir, os.ModePerm)
 
-	// Fichiers de sortie (out)
-	report, _ := os.Create(cfg.OutDir + "/report.txt")
-	index, _ := os.Create(cfg.OutDir + "/index.txt")
-	merged, _ := os.Create(cfg.OutDir + "/merged.txt")
-
-	defer report.Close()
-	defer index.Close()
+	// Fichiers de sortie (out), écrits dans l'encodage choisi
+	// report.<format> et index.<format> sont écrits à la fin, quand toutes les stats sont connues
+	merged, err := createTextOutput(cfg.OutDir+"/merged.txt", cfg.OutputEncoding)
+	if err != nil {
+		fmt.Println("Erreur création fichier :", err)
+		return
+	}
 	defer merged.Close()
 
-	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	// Filtres : extensions, motifs include / exclude, profondeur, taille, fichiers cachés, .gitignore
+	filter := newScanFilter(cfg, dir)
+	filter.archives = true
+
+	// Liste des fichiers à analyser, les archives sont parcourues comme des dossiers
+	var entries []scanEntry
+	err = walkFiltered(cfg, dir, filter, func(path string, info os.FileInfo) {
+		if !isArchive(path) {
+			entries = append(entries, fileEntry(path, info))
+			return
+		}
+		members, err := archiveEntries(path, filter.keepMember(path))
 		if err != nil {
-			return nil
+			fmt.Println("Erreur archive", path, ":", err)
 		}
+		entries = append(entries, members...)
+	})
+	if err != nil {
+		fmt.Println("Erreur parcours :", err)
+		return
+	}
 
-		// On ne traite que les fichiers avec l'extension par défaut
-		if !info.IsDir() && strings.HasSuffix(path, cfg.DefaultExt) {
-
-			index.WriteString(fmt.Sprintf("%s | %d bytes | %s\n",
-				path, info.Size(), info.ModTime().Format(time.RFC3339)))
+	// Cache : les fichiers inchangés depuis le dernier scan reprennent leurs statistiques
+	analyze := func(e scanEntry) fileResult { return analyzeEntry(e, cfg.HTMLReport) }
+	var cache *scanCache
+	if cfg.ScanCache {
+		cache = loadScanCache(cfg, dir, cfg.Rescan)
+		analyze = cache.analyze
+	}
 
-			file, err := os.Open(path)
+	// Analyse en parallèle, écriture des résultats dans l'ordre des fichiers
+	var records []fileRecord
+	var htmlFiles []htmlFile
+	totalFreq := make(map[string]int)
+	first := true
+	cached := 0
+	scanEntries(entries, cfg.Concurrency, analyze, func(r fileResult) {
+		if cache != nil {
+			cache.store(r)
+		}
+		if r.Cached {
+			cached++
+		}
+		records = append(records, r.record())
 
-			// Vérifier l'ouverture du fichier
-			if err != nil {
-				return nil
+		// Détail du fichier pour le rapport HTML
+		if cfg.HTMLReport {
+			htmlFiles = append(htmlFiles, newHTMLFile(r.record(), r.Freq))
+			for w, c := range r.Freq {
+				totalFreq[w] += c
 			}
+		}
 
-			// Compter les lignes
-			sc := bufio.NewScanner(file)
-			lines := 0
-			for sc.Scan() {
-				lines++
-			}
-			report.WriteString(fmt.Sprintf("%s : %d lignes\n", path, lines))
+		// Vérifier la lecture du fichier
+		if r.Err != nil {
+			fmt.Println("Erreur lecture", r.Entry.Path, ":", r.Err)
+			return
+		}
 
-			// Revenir au début du fichier pour la copie
-			file.Seek(0, 0)
-			_, err = io.Copy(merged, file)
-			if err != nil {
-				fmt.Println("Erreur copie:", err)
-			}
-			// Vérifier que le fichier se termine par un '\n'
-			merged.WriteString("\n")
+		// Copie du texte décodé, réencodé dans l'encodage de sortie, avec en-tête et marqueurs
+		if err := writeMergedEntry(merged, cfg, r, first); err != nil {
+			fmt.Println("Erreur copie:", err)
 		}
-		return nil
+		first = false
 	})
+	// Rapport par fichier et index dans le format choisi (text, json, csv, markdown)
+	for _, r := range []struct {
+		base  string
+		table reportTable
+	}{{"report", fileRecordsTable(records)}, {"index", indexTable(records)}} {
+		files, err := writeReport(cfg, r.base, []reportTable{r.table})
+		if err != nil {
+			fmt.Println("Erreur rapport :", err)
+		}
+		for _, f := range files {
+			fmt.Println("Rapport généré :", f)
+		}
+	}
+
+	// Changements depuis le scan précédent, puis mise à jour du cache
+	if cache != nil {
+		table, n := cache.changesTable()
+		fmt.Printf("Cache : %d fichier(s) repris sans réanalyse sur %d\n", cached, len(entries))
+		fmt.Printf("Depuis le dernier scan : %d nouveau(x), %d modifié(s), %d supprimé(s), %d inchangé(s)\n",
+			n.New, n.Modified, n.Deleted, n.Unchanged)
+		files, err := writeReport(cfg, "changes", []reportTable{table})
+		if err != nil {
+			fmt.Println("Erreur rapport :", err)
+		}
+		for _, f := range files {
+			fmt.Println("Rapport généré :", f)
+		}
+		if err := cache.save(); err != nil {
+			fmt.Println("Erreur écriture du cache :", err)
+		}
+	}
+
+	// Rapport HTML : récapitulatif, mots les plus fréquents et détail par fichier
+	if cfg.HTMLReport {
+		var size int64
+		lines, words, errs := 0, 0, 0
+		for _, r := range records {
+			size += r.Size
+			lines += r.Lines
+			words += r.Words
+			if r.Error != "" {
+				errs++
+			}
+		}
+		page := htmlReport{
+			Title: "Analyse multi-fichiers de " + dir,
+			Summary: [][2]string{
+				{"Dossier", dir},
+				{"Fichiers analysés", fmt.Sprint(len(records))},
+				{"Taille totale", fmt.Sprint(size, " octets")},
+				{"Lignes", fmt.Sprint(lines)},
+				{"Mots", fmt.Sprint(words)},
+				{"Erreurs de lecture", fmt.Sprint(errs)},
+			},
+			Chart: wordChart(topWords(totalFreq, htmlTopWords)),
+			Files: htmlFiles,
+		}
+		if p, err := writeHTMLReport(cfg, "report.html", page); err != nil {
+			fmt.Println("Erreur rapport HTML :", err)
+		} else {
+			fmt.Println("Rapport HTML généré :", p)
+		}
+	}
 	fmt.Println("Analyse multi-fichiers terminée.")
 }
 
@@ -278,7 +626,7 @@ func choixB(cfg Config, reader *bufio.Reader) {
 
 // Cette fonction :
 // 1) Demande un nom d'article
-// 2) Télécharge la page HTML depuis Wikipédia
+// 2) Télécharge l'article par l'API MediaWiki, ou la page HTML en secours (édition wiki_lang, adresse wiki_base_url)
 // 3) Extrait le texte des balises <p>
 // 4) Calcule des statistiques sur les mots
 // 5) Sauvegarde les paragraphes filtrés dans un fichier
@@ -296,70 +644,81 @@ func choixWiki(cfg Config, reader *bufio.Reader) {
 		return
 	}
 
-	// Construction de l'URL vers Wikipédia
-	url := "https://fr.wikipedia.org/wiki/" + article
-	fmt.Println("Téléchargement de :", url)
-
-	// Création d’un client HTTP
-	client := &http.Client{}
-
-	// Création d’une requête GET vers l’URL de l’article
-	req, err := http.NewRequest("GET", url, nil)
+	// Titre normalisé (Pikachu, Go_(langage)...), encodé dans l'URL et nettoyé pour les noms de fichiers
+	article, err := normalizeWikiTitle(article)
 	if err != nil {
-		fmt.Println("Erreur création requête :", err)
+		fmt.Println("Erreur :", err)
 		return
 	}
 
-	// Ajout d’un User-Agent pour simuler un navigateur afin de ne pas se faire bloquer( ici mozzila )
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64)")
-
-	// Envoi de la requête HTTP et télécharge tout le HTML de la page Wikipédia
-	resp, err := client.Do(req)
+	// Téléchargement par l'API MediaWiki (page HTML analysée avec goquery en secours)
+	fmt.Println("Téléchargement de :", wikiArticleURL(cfg, article))
+	page, err := fetchWikiPage(cfg, article)
 	if err != nil {
 		fmt.Println("Erreur téléchargement :", err)
 		return
 	}
-	defer resp.Body.Close()
-
-	// Vérification du code HTTP, 200 = OK et 404 = page inexistante
-	if resp.StatusCode != 200 {
-		fmt.Println("Erreur HTTP :", resp.Status)
-		return
+	doc := page.Doc
+	url := page.URL
+
+	// Titre canonique (après redirection), révision et date
+	article = page.Title
+	fmt.Println("Article :", displayWikiTitle(article), "(source :", page.Source+")")
+	if page.RevID > 0 {
+		fmt.Println("Révision :", page.RevID)
+	}
+	if !page.Modified.IsZero() {
+		fmt.Println("Dernière modification :", page.Modified.Format(time.RFC3339))
 	}
 
-	// Analyse du HTML avec goquery qui va construire un DOM à partir du HTML téléchargé
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		fmt.Println("Erreur analyse HTML :", err)
-		return
+	// Liens, catégories, infobox et références, lus avant le nettoyage du texte
+	data := extractWikiData(cfg, page)
+	fmt.Printf("Liens internes : %d, liens externes : %d, catégories : %d, références : %d, champs d'infobox : %d\n",
+		len(data.InternalLinks), len(data.ExternalLinks), len(data.Categories), len(data.References), len(data.Infobox))
+
+	// Blocs de texte du contenu de l'article (sans menus ni pied de page) :
+	// paragraphes, et selon la config titres, listes et légendes de tableaux.
+	// Les appels de note [1], les liens "modifier" et les éléments cachés sont retirés.
+	// Chaque bloc connaît sa section (titres h2, h3... qui le précèdent).
+	opts := wikiOptions(cfg)
+	blocks := extractWikiBlocks(doc, opts)
+
+	// Choix d'une section : elle est gardée avec ses sous-sections
+	sections := wikiSections(blocks)
+	if len(sections) > 1 {
+		fmt.Println("Sections :")
+		for _, name := range sections {
+			fmt.Println(" -", sectionName(name))
+		}
+		fmt.Print("Section à garder (ENTER = toutes) : ")
+		section, _ := reader.ReadString('\n')
+		if section = strings.TrimSpace(section); section != "" {
+			blocks = filterWikiSection(blocks, section)
+			if len(blocks) == 0 {
+				fmt.Println("Section introuvable :", section)
+				return
+			}
+		}
 	}
 
-	// Le tableau qui va contenir le TEXTE des paragraphes
+	// Le tableau qui va contenir le TEXTE des blocs, sans mise en forme, pour les statistiques
 	var lines []string
-
-	// doc.Find("p") sélectionne TOUTES les balises <p> de la page HTML
-	// Cela inclut :
-	// - Le texte principal de l'article
-	// - Les paragraphes secondaires
-	// - Les éventuels paragraphes hors contenu principal
-	doc.Find("p").Each(func(i int, s *goquery.Selection) {
-
-		// s.Text() récupère tout le texte contenu dans la balise <p>
-		// Voici ce qui est inclus dedans :
-		// Le texte normal
-		// Le texte des liens <a>
-		// Le texte des <span>
-		// Les références [1], [2] dans les <sup>
-		text := strings.TrimSpace(s.Text())
-
-		// On ignore les paragraphes vides
-		if text != "" {
-			lines = append(lines, text)
+	paragraphs := 0
+	for _, b := range blocks {
+		if !b.shown(opts) {
+			continue
 		}
-	})
+		lines = append(lines, b.Text)
+		if b.Kind == "p" {
+			paragraphs++
+		}
+	}
 
 	// Affiche combien de paragraphes ont été extraits
-	fmt.Println("Paragraphes extraits :", len(lines))
+	fmt.Println("Paragraphes extraits :", paragraphs)
+	if len(lines) > paragraphs {
+		fmt.Println("Titres, éléments de liste et légendes :", len(lines)-paragraphs)
+	}
 
 	// Nombre total de mots détecté Somme des longueurs de tous les mots
 	totalWords := 0
@@ -392,6 +751,49 @@ func choixWiki(cfg Config, reader *bufio.Reader) {
 		fmt.Println("Longueur moyenne :", totalLen/totalWords)
 	}
 
+	// Statistiques par section
+	sectionTable := wikiSectionsTable(blocks)
+	fmt.Println("Par section :")
+	for _, r := range sectionTable.Rows {
+		fmt.Printf(" %-40s %3d bloc(s) %6d mot(s)\n", r[0], r[2], r[3])
+	}
+
+	// Langue détectée sur l'ensemble des paragraphes
+	lang := detectLanguage(strings.Join(lines, "\n"))
+	fmt.Println("Langue détectée :", lang)
+
+	// Rapport HTML de la page
+	if cfg.HTMLReport {
+		os.MkdirAll(cfg.OutDir, os.ModePerm)
+		avg := 0
+		if totalWords > 0 {
+			avg = totalLen / totalWords
+		}
+		report := htmlReport{
+			Title: "Wikipédia : " + displayWikiTitle(article),
+			Summary: [][2]string{
+				{"Article", displayWikiTitle(article)},
+				{"URL", url},
+				{"Révision", fmt.Sprint(page.RevID)},
+				{"Dernière modification", formatCell(page.Modified)},
+				{"Paragraphes", fmt.Sprint(paragraphs)},
+			},
+			Chart: wordChart(topWords(wordFrequencies(strings.Join(lines, "\n"), lang.Lang), htmlTopWords)),
+		}
+		if len(lines) > paragraphs {
+			report.Summary = append(report.Summary, [2]string{"Titres, éléments de liste et légendes", fmt.Sprint(len(lines) - paragraphs)})
+		}
+		report.Summary = append(report.Summary,
+			[2]string{"Mots", fmt.Sprint(totalWords)},
+			[2]string{"Longueur moyenne", fmt.Sprint(avg)},
+			[2]string{"Langue", lang.String()})
+		if p, err := writeHTMLReport(cfg, "wiki_"+safeFileName(article)+".html", report); err != nil {
+			fmt.Println("Erreur rapport HTML :", err)
+		} else {
+			fmt.Println("Rapport HTML généré :", p)
+		}
+	}
+
 	// FILTRAGE PAR MOT-CLÉ
 	fmt.Print("Mot-clé pour filtrer (ENTER = aucun) : ")
 	keyword, _ := reader.ReadString('\n')
@@ -401,7 +803,7 @@ func choixWiki(cfg Config, reader *bufio.Reader) {
 	os.MkdirAll(cfg.OutDir, os.ModePerm)
 
 	// Création du fichier :
-	outFile := filepath.Join(cfg.OutDir, "wiki_"+article+".txt")
+	outFile := filepath.Join(cfg.OutDir, "wiki_"+safeFileName(article)+".txt")
 	f, err := os.Create(outFile)
 	if err != nil {
 		fmt.Println("Erreur création fichier :", err)
@@ -411,12 +813,15 @@ func choixWiki(cfg Config, reader *bufio.Reader) {
 
 	// ÉCRITURE DES DONNÉES
 	count := 0
-	for _, l := range lines {
+	for _, b := range blocks {
+		if !b.shown(opts) {
+			continue
+		}
 
 		// Si aucun mot-clé alors on écrit tout
-		// Sinon, on écrit uniquement les paragraphes contenant le mot-clé
-		if keyword == "" || strings.Contains(l, keyword) {
-			f.WriteString(l + "\n")
+		// Sinon, on écrit uniquement les blocs contenant le mot-clé
+		if keyword == "" || strings.Contains(b.Text, keyword) {
+			f.WriteString(b.String() + "\n")
 
 			if keyword != "" {
 				count++
@@ -429,6 +834,91 @@ func choixWiki(cfg Config, reader *bufio.Reader) {
 	if keyword != "" {
 		fmt.Println("Lignes contenant le mot-clé :", count)
 	}
+
+	// Export Markdown avec les titres des sections (sans filtre par mot-clé, pour garder la structure)
+	mdFile := filepath.Join(cfg.OutDir, "wiki_"+safeFileName(article)+".md")
+	if err := writeTextFile(mdFile, wikiMarkdown(displayWikiTitle(article), url, blocks), cfg.OutputEncoding); err != nil {
+		fmt.Println("Erreur écriture :", err)
+	} else {
+		fmt.Println("Fichier généré :", mdFile)
+	}
+
+	// Données structurées de l'article
+	dataFile := filepath.Join(cfg.OutDir, "wiki_"+safeFileName(article)+".json")
+	if err := writeWikiData(dataFile, data); err != nil {
+		fmt.Println("Erreur écriture :", err)
+	} else {
+		fmt.Println("Fichier généré :", dataFile)
+	}
+
+	// Statistiques par section
+	written, err := writeReport(cfg, "wiki_"+safeFileName(article)+"_sections", []reportTable{sectionTable})
+	if err != nil {
+		fmt.Println("Erreur rapport :", err)
+	}
+	for _, f := range written {
+		fmt.Println("Rapport généré :", f)
+	}
+}
+
+// CHOIX C : un article, un lot d'articles ou une exploration des liens
+func choixWikiMenu(cfg Config, reader *bufio.Reader) {
+	for {
+		fmt.Println("\n-------- Wikipédia --------")
+		fmt.Println()
+		fmt.Println("1 - Analyser un article")
+		fmt.Println("2 - Télécharger une liste d'articles (fichier)")
+		fmt.Println("3 - Explorer les liens à partir d'un article")
+		fmt.Println("4 - Retour au menu principal")
+		fmt.Println()
+		fmt.Print("Choix : ")
+		choice, _ := reader.ReadString('\n')
+
+		switch strings.TrimSpace(choice) {
+		case "1":
+			choixWiki(cfg, reader)
+		case "2":
+			choixWikiBatch(cfg, reader)
+		case "3":
+			choixWikiCrawl(cfg, reader)
+		case "4":
+			return
+		default:
+			fmt.Println("Choix invalide.")
+		}
+	}
+}
+
+// CHOIX F : outils sur les dossiers
+func choixOutils(cfg Config, reader *bufio.Reader) {
+	for {
+		fmt.Println("\n-------- Outils dossiers --------")
+		fmt.Println()
+		fmt.Println("1 - Découper un merged.txt en fichiers")
+		fmt.Println("2 - Rechercher les doublons")
+		fmt.Println("3 - Occupation disque (du)")
+		fmt.Println("4 - Comparer deux dossiers")
+		fmt.Println("5 - Retour au menu principal")
+		fmt.Println()
+		fmt.Print("Choix : ")
+		choice, _ := reader.ReadString('\n')
+		choice = strings.TrimSpace(choice)
+
+		switch choice {
+		case "1":
+			choixSplit(cfg, reader)
+		case "2":
+			choixDuplicates(cfg, reader)
+		case "3":
+			choixDiskUsage(cfg, reader)
+		case "4":
+			choixDirDiff(cfg, reader)
+		case "5":
+			return
+		default:
+			fmt.Println("Choix invalide.")
+		}
+	}
 }
 
 // ------- 14/ 20 : ProcessOps --------
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ------- Fichiers compressés et archives --------
+// .gz et .bz2 sont décompressés à la volée, .zip / .tar / .tar.gz / .tgz / .tar.bz2
+// sont parcourus comme des dossiers : un membre est désigné par archive.zip/chemin/interne.txt
+
+// Indique si le nom correspond à une archive parcourue comme un dossier
+func isArchive(name string) bool {
+	n := strings.ToLower(name)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz", ".tar.bz2", ".tbz2"} {
+		if strings.HasSuffix(n, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Retire l'extension de compression (.gz, .bz2) : "app.log.gz" -> "app.log"
+func stripCompressionExt(name string) string {
+	n := strings.ToLower(name)
+	for _, ext := range []string{".gz", ".bz2"} {
+		if strings.HasSuffix(n, ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return name
+}
+
+// Ajoute la décompression gzip / bzip2 selon l'extension du nom
+func decompressReader(name string, r io.ReadCloser) (io.ReadCloser, error) {
+	n := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(n, ".gz"):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		return readCloser{gz, func() error { gz.Close(); return r.Close() }}, nil
+	case strings.HasSuffix(n, ".bz2"):
+		return readCloser{bzip2.NewReader(r), r.Close}, nil
+	}
+	return r, nil
+}
+
+// Associe un Reader à une fonction de fermeture
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (rc readCloser) Close() error {
+	return rc.close()
+}
+
+// Ouvre un fichier du disque en le décompressant si besoin
+func openCompressed(p string) (io.ReadCloser, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	return decompressReader(p, f)
+}
+
+// Entrée pour un fichier du disque (éventuellement .gz / .bz2)
+func fileEntry(p string, info os.FileInfo) scanEntry {
+	return scanEntry{
+		Path:    p,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		open:    func() (io.ReadCloser, error) { return openCompressed(p) },
+	}
+}
+
+// Liste les fichiers d'une archive (zip ou tar, compressé ou non) acceptés par keep (nom et taille du membre)
+// Seuls les noms et positions des membres sont gardés : le contenu est lu à la demande (read)
+func archiveEntries(archivePath string, keep func(name string, size int64) bool) ([]scanEntry, error) {
+	if strings.HasSuffix(strings.ToLower(archivePath), ".zip") {
+		return zipEntries(archivePath, keep)
+	}
+	return tarEntries(archivePath, keep)
+}
+
+// Fichier du disque partagé par les membres d'une archive : ouvert au premier membre lu,
+// fermé quand plus aucun membre n'est en cours de lecture
+type sharedFile struct {
+	mu   sync.Mutex
+	path string
+	f    *os.File
+	refs int
+}
+
+func (s *sharedFile) acquire() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refs == 0 {
+		f, err := os.Open(s.path)
+		if err != nil {
+			return err
+		}
+		s.f = f
+	}
+	s.refs++
+	return nil
+}
+
+func (s *sharedFile) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refs--; s.refs == 0 {
+		s.f.Close()
+		s.f = nil
+	}
+}
+
+// Lecture à une position, pour zip.NewReader (seulement entre acquire et release)
+func (s *sharedFile) ReadAt(b []byte, off int64) (int, error) {
+	s.mu.Lock()
+	f := s.f
+	s.mu.Unlock()
+	if f == nil {
+		return 0, os.ErrClosed
+	}
+	return f.ReadAt(b, off)
+}
+
+// Membres d'une archive zip : le répertoire central est lu une fois, chaque membre garde son *zip.File
+func zipEntries(archivePath string, keep func(name string, size int64) bool) ([]scanEntry, error) {
+	sf := &sharedFile{path: archivePath}
+	if err := sf.acquire(); err != nil {
+		return nil, err
+	}
+	defer sf.release()
+	info, err := sf.f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(sf, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []scanEntry
+	for _, zf := range zr.File {
+		size := int64(zf.UncompressedSize64)
+		if zf.FileInfo().IsDir() || !keep(zf.Name, size) {
+			continue
+		}
+		entries = append(entries, scanEntry{
+			Path:    filepath.Join(archivePath, filepath.FromSlash(zf.Name)),
+			Size:    size,
+			ModTime: zf.Modified,
+			open: func() (io.ReadCloser, error) {
+				if err := sf.acquire(); err != nil {
+					return nil, err
+				}
+				rc, err := zf.Open()
+				if err != nil {
+					sf.release()
+					return nil, err
+				}
+				return decompressReader(zf.Name, readCloser{rc, func() error {
+					err := rc.Close()
+					sf.release()
+					return err
+				}})
+			},
+		})
+	}
+	return entries, nil
+}
+
+// Ouvre le flux d'un tar, décompressé selon l'extension (.tar.gz, .tgz, .tar.bz2, .tbz2)
+func openTar(archivePath string) (io.ReadCloser, error) {
+	n := strings.ToLower(archivePath)
+	if strings.HasSuffix(n, ".tgz") {
+		n = strings.TrimSuffix(n, ".tgz") + ".gz"
+	} else if strings.HasSuffix(n, ".tbz2") {
+		n = strings.TrimSuffix(n, ".tbz2") + ".bz2"
+	}
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	return decompressReader(n, f)
+}
+
+// Compte les octets lus : position des données d'un membre dans le flux du tar
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// Flux décompressé d'un tar compressé, partagé par ses membres. Les workers lisent les membres
+// dans l'ordre de la liste : la lecture reprend où la précédente s'est arrêtée, une lecture en arrière
+// relance la décompression depuis le début. Le flux est fermé quand chaque membre a été lu.
+type tarStream struct {
+	mu     sync.Mutex
+	path   string
+	rc     io.ReadCloser
+	pos    int64
+	unread int
+}
+
+// Lit size octets à la position offset du flux décompressé
+func (s *tarStream) read(offset, size int64) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.unread--
+	data, err := s.readAt(offset, size)
+	if err != nil || s.unread <= 0 {
+		s.close()
+	}
+	return data, err
+}
+
+func (s *tarStream) readAt(offset, size int64) ([]byte, error) {
+	if s.rc == nil || offset < s.pos {
+		s.close()
+		rc, err := openTar(s.path)
+		if err != nil {
+			return nil, err
+		}
+		s.rc = rc
+	}
+	if _, err := io.CopyN(io.Discard, s.rc, offset-s.pos); err != nil {
+		return nil, err
+	}
+	s.pos = offset
+	data := make([]byte, size)
+	if _, err := io.ReadFull(s.rc, data); err != nil {
+		return nil, err
+	}
+	s.pos += size
+	return data, nil
+}
+
+func (s *tarStream) close() {
+	if s.rc != nil {
+		s.rc.Close()
+		s.rc, s.pos = nil, 0
+	}
+}
+
+// Membres d'une archive tar (.tar, .tar.gz, .tgz, .tar.bz2, .tbz2) : nom et position des données.
+// Un .tar est relu directement à la position du membre, un tar compressé par un flux partagé (tarStream)
+func tarEntries(archivePath string, keep func(name string, size int64) bool) ([]scanEntry, error) {
+	rc, err := openTar(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var entries []scanEntry
+	compressed := !strings.HasSuffix(strings.ToLower(archivePath), ".tar")
+	stream := &tarStream{path: archivePath}
+	cr := &countingReader{r: rc}
+	tr := tar.NewReader(cr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return entries, err
+		}
+		if hdr.Typeflag != tar.TypeReg || !keep(hdr.Name, hdr.Size) {
+			continue
+		}
+		name, offset, size := hdr.Name, cr.n, hdr.Size
+		open := func() (io.ReadCloser, error) {
+			f, err := os.Open(archivePath)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := f.Seek(offset, io.SeekStart); err != nil {
+				f.Close()
+				return nil, err
+			}
+			return decompressReader(name, readCloser{io.LimitReader(f, size), f.Close})
+		}
+		if compressed {
+			stream.unread++
+			open = func() (io.ReadCloser, error) {
+				data, err := stream.read(offset, size)
+				if err != nil {
+					return nil, err
+				}
+				return decompressReader(name, io.NopCloser(bytes.NewReader(data)))
+			}
+		}
+		entries = append(entries, scanEntry{
+			Path:    filepath.Join(archivePath, filepath.FromSlash(path.Clean(name))),
+			Size:    size,
+			ModTime: hdr.ModTime,
+			open:    open,
+		})
+	}
+	return entries, nil
+}
+
+// Découpe un chemin "dossier/logs.zip/interne/app.log" en (archive, membre)
+// Retourne ok=false si aucun préfixe du chemin n'est une archive existante
+func splitArchivePath(p string) (string, string, bool) {
+	parts := strings.Split(filepath.ToSlash(p), "/")
+	for i := 1; i < len(parts); i++ {
+		prefix := filepath.FromSlash(strings.Join(parts[:i], "/"))
+		if !isArchive(prefix) {
+			continue
+		}
+		if info, err := os.Stat(prefix); err == nil && !info.IsDir() {
+			return prefix, strings.Join(parts[i:], "/"), true
+		}
+	}
+	return "", "", false
+}
+
+// Retrouve l'entrée correspondant à un fichier du disque, un fichier compressé
+// ou un membre d'archive désigné par archive.zip/chemin/interne
+func resolveInput(p string) (scanEntry, error) {
+	if info, err := os.Stat(p); err == nil {
+		if info.IsDir() {
+			return scanEntry{}, fmt.Errorf("%s est un dossier", p)
+		}
+		return fileEntry(p, info), nil
+	}
+
+	archivePath, member, ok := splitArchivePath(p)
+	if !ok {
+		return scanEntry{}, fmt.Errorf("fichier introuvable : %s", p)
+	}
+	entries, err := archiveEntries(archivePath, func(name string, _ int64) bool {
+		return path.Clean(name) == path.Clean(member)
+	})
+	if err != nil {
+		return scanEntry{}, err
+	}
+	if len(entries) == 0 {
+		return scanEntry{}, fmt.Errorf("membre introuvable dans %s : %s", archivePath, member)
+	}
+	return entries[0], nil
+}
+
+// Pour une archive entière, demande quel membre analyser (directement s'il n'y en a qu'un)
+func chooseArchiveMember(reader *bufio.Reader, archivePath string) (scanEntry, error) {
+	entries, err := archiveEntries(archivePath, func(string, int64) bool { return true })
+	if err != nil {
+		return scanEntry{}, err
+	}
+	if len(entries) == 0 {
+		return scanEntry{}, fmt.Errorf("archive vide : %s", archivePath)
+	}
+	if len(entries) == 1 {
+		return entries[0], nil
+	}
+
+	fmt.Println("Membres de l'archive :")
+	for i, e := range entries {
+		fmt.Printf("%d - %s (%d bytes)\n", i+1, e.Path, e.Size)
+	}
+	fmt.Print("Numéro du membre à analyser : ")
+	nStr, _ := reader.ReadString('\n')
+	n, err := strconv.Atoi(strings.TrimSpace(nStr))
+	if err != nil || n < 1 || n > len(entries) {
+		return scanEntry{}, fmt.Errorf("numéro invalide")
+	}
+	// Le membre choisi est relu seul : les autres ne seront pas lus
+	return resolveInput(entries[n-1].Path)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Pas d'écrivain bzip2 dans la bibliothèque standard : fichiers produits par bzip2 -9
+const (
+	// "bonjour le monde\n"
+	bz2Text = "QlpoOTFBWSZTWV0CSYkAAAHRgAAQQAAWF5IAIAAiABkEDQNCSzi9e0KEHp8XckU4UJBdAkmJ"
+	// tar de a.txt ("alpha\n") et sub/b.txt ("beta\n")
+	bz2Tar = "QlpoOTFBWSZTWd4l+iwAAJV7hMkQAGBAAf+AAIhyRN5AAACAiCAAkoSqPSGgyDTQAeoAqiTU00AZDQGg0yZy2JUWyCawiIX4Xri5XCi03JRBRGKkWMlHas1aENSQx66R0q1qWOtFkyQaDhCE43SRlJNKG8c/UNvGQWOQKCsHHJUfWs0v2b8nNe1og/i7kinChIbxL9Fg"
+)
+
+// Membres des archives de test, dans l'ordre
+var archiveFiles = []struct{ name, content string }{
+	{"a.txt", "alpha\n"},
+	{"sub/b.txt", "beta\n"},
+	{"sub/c.log", strings.Repeat("gamma ", 2000) + "\n"},
+	{"d.txt.gz", "delta\n"}, // compressé dans l'archive : décompressé à la lecture
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write(data)
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&tar.Header{Name: "sub/", Typeflag: tar.TypeDir, Mode: 0755})
+	for _, f := range archiveFiles {
+		data := []byte(f.content)
+		if strings.HasSuffix(f.name, ".gz") {
+			data = gzipBytes(t, data)
+		}
+		hdr := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(data)), ModTime: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write(data)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	zw.Create("sub/")
+	for _, f := range archiveFiles {
+		data := []byte(f.content)
+		if strings.HasSuffix(f.name, ".gz") {
+			data = gzipBytes(t, data)
+		}
+		w, err := zw.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeTestFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func readEntry(t *testing.T, e scanEntry) string {
+	t.Helper()
+	data, err := e.read()
+	if err != nil {
+		t.Fatalf("%s : %v", e.Path, err)
+	}
+	return string(data)
+}
+
+func TestCompressedFiles(t *testing.T) {
+	dir := t.TempDir()
+	bz2, _ := base64.StdEncoding.DecodeString(bz2Text)
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"plain.txt", []byte("texte simple\n"), "texte simple\n"},
+		{"app.log.gz", gzipBytes(t, []byte("bonjour le monde\n")), "bonjour le monde\n"},
+		{"app.log.bz2", bz2, "bonjour le monde\n"},
+	}
+	for _, tt := range tests {
+		p := writeTestFile(t, dir, tt.name, tt.data)
+		e, err := resolveInput(p)
+		if err != nil {
+			t.Fatalf("%s : %v", tt.name, err)
+		}
+		if got := readEntry(t, e); got != tt.want {
+			t.Errorf("%s : %q, attendu %q", tt.name, got, tt.want)
+		}
+		if e.Size != int64(len(tt.data)) {
+			t.Errorf("%s : taille %d, attendu la taille sur disque %d", tt.name, e.Size, len(tt.data))
+		}
+	}
+}
+
+func TestArchiveEntries(t *testing.T) {
+	dir := t.TempDir()
+	tarData := tarBytes(t)
+	bz2, _ := base64.StdEncoding.DecodeString(bz2Tar)
+	archives := map[string][]byte{
+		"a.zip":     zipBytes(t),
+		"a.tar":     tarData,
+		"a.tar.gz":  gzipBytes(t, tarData),
+		"a.tgz":     gzipBytes(t, tarData),
+		"b.tar.bz2": bz2,
+	}
+	for name, data := range archives {
+		p := writeTestFile(t, dir, name, data)
+		want := archiveFiles
+		if strings.HasSuffix(name, ".bz2") {
+			want = archiveFiles[:2]
+		}
+
+		entries, err := archiveEntries(p, func(string, int64) bool { return true })
+		if err != nil {
+			t.Fatalf("%s : %v", name, err)
+		}
+		if len(entries) != len(want) {
+			t.Fatalf("%s : %d membre(s), attendu %d", name, len(entries), len(want))
+		}
+		// Lecture en arrière puis en avant : un tar compressé relance alors sa décompression
+		for _, i := range []int{len(want) - 1, 0, 1} {
+			e, f := entries[i], want[i]
+			if wantPath := filepath.Join(p, filepath.FromSlash(f.name)); e.Path != wantPath {
+				t.Errorf("%s : membre %q, attendu %q", name, e.Path, wantPath)
+			}
+			if got := readEntry(t, e); got != f.content {
+				t.Errorf("%s/%s : %.40q, attendu %.40q", name, f.name, got, f.content)
+			}
+		}
+
+		// Filtre sur le nom et la taille du membre
+		var kept []string
+		entries, err = archiveEntries(p, func(n string, size int64) bool { return strings.HasSuffix(n, ".txt") && size < 100 })
+		if err != nil {
+			t.Fatalf("%s : %v", name, err)
+		}
+		for _, e := range entries {
+			kept = append(kept, filepath.ToSlash(strings.TrimPrefix(e.Path, p)))
+		}
+		if got := strings.Join(kept, " "); got != "/a.txt /sub/b.txt" {
+			t.Errorf("%s : membres filtrés %q", name, got)
+		}
+
+		// Membre désigné par archive/chemin/interne
+		e, err := resolveInput(filepath.Join(p, "sub", "b.txt"))
+		if err != nil {
+			t.Fatalf("%s : %v", name, err)
+		}
+		if got := readEntry(t, e); got != "beta\n" {
+			t.Errorf("%s/sub/b.txt : %q", name, got)
+		}
+	}
+}
+
+// Les workers lisent les membres en même temps (go test -race)
+func TestArchiveEntriesConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	tarData := tarBytes(t)
+	for name, data := range map[string][]byte{"a.zip": zipBytes(t), "a.tar": tarData, "a.tar.gz": gzipBytes(t, tarData)} {
+		p := writeTestFile(t, dir, name, data)
+		entries, err := archiveEntries(p, func(string, int64) bool { return true })
+		if err != nil {
+			t.Fatal(err)
+		}
+		var mu sync.Mutex
+		var got []string
+		var wg sync.WaitGroup
+		for _, e := range entries {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				data, err := e.read()
+				if err != nil {
+					t.Errorf("%s : %v", e.Path, err)
+				}
+				mu.Lock()
+				got = append(got, string(data))
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		var want []string
+		for _, f := range archiveFiles {
+			want = append(want, f.content)
+		}
+		sort.Strings(got)
+		sort.Strings(want)
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("%s : contenus lus en parallèle différents", name)
+		}
+	}
+}
+
+func TestCorruptArchives(t *testing.T) {
+	dir := t.TempDir()
+	tarData := tarBytes(t)
+	tgz := gzipBytes(t, tarData)
+	zipData := zipBytes(t)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"vide.zip", nil},
+		{"aleatoire.zip", []byte("ceci n'est pas une archive zip")},
+		{"tronque.zip", zipData[:len(zipData)/2]},
+		{"aleatoire.tar.gz", []byte("ceci n'est pas du gzip")},
+		{"tronque.tar.gz", tgz[:len(tgz)/2]},
+		{"tronque.tar", tarData[:700]}, // coupé dans l'en-tête de a.txt
+	}
+	for _, tt := range tests {
+		p := writeTestFile(t, dir, tt.name, tt.data)
+		entries, err := archiveEntries(p, func(string, int64) bool { return true })
+		if err == nil {
+			// Liste lisible mais contenu coupé : l'erreur vient à la lecture
+			for _, e := range entries {
+				if _, rerr := e.read(); rerr != nil {
+					err = rerr
+				}
+			}
+		}
+		if err == nil {
+			t.Errorf("%s : erreur attendue", tt.name)
+		}
+	}
+
+	// Fichier .gz abîmé
+	p := writeTestFile(t, dir, "abime.txt.gz", []byte("pas du gzip"))
+	e, err := resolveInput(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := e.read(); err == nil {
+		t.Error("abime.txt.gz : erreur attendue")
+	}
+}
+
+// Nombre de descripteurs ouverts par le processus, -1 si /proc n'est pas disponible
+func openFDs() int {
+	fds, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(fds)
+}
+
+// Aucun fichier ne reste ouvert une fois les membres lus : flux partagé d'un tar compressé refermé,
+// fichier d'un zip fermé quand plus aucun membre n'est en lecture
+func TestArchiveFilesClosed(t *testing.T) {
+	if openFDs() < 0 {
+		t.Skip("/proc/self/fd indisponible")
+	}
+	dir := t.TempDir()
+	tarData := tarBytes(t)
+	for name, data := range map[string][]byte{"a.zip": zipBytes(t), "a.tar": tarData, "a.tar.gz": gzipBytes(t, tarData)} {
+		p := writeTestFile(t, dir, name, data)
+		before := openFDs()
+		entries, err := archiveEntries(p, func(string, int64) bool { return true })
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			readEntry(t, e)
+		}
+		// Relecture d'un membre : le flux est rouvert puis refermé
+		if got := readEntry(t, entries[1]); got != archiveFiles[1].content {
+			t.Errorf("%s : relecture %q", name, got)
+		}
+		if after := openFDs(); after != before {
+			t.Errorf("%s : %d descripteur(s) encore ouvert(s)", name, after-before)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ------- Cache des analyses multi-fichiers --------
+// Les statistiques de chaque fichier sont gardées dans OutDir/scan_cache.json, par dossier analysé.
+// Un fichier dont la taille et la date (ou, avec scan_cache_hash, la taille et l'empreinte) n'ont pas changé
+// reprend ses statistiques au lieu d'être réanalysé, et le scan indique ce qui a changé depuis le précédent.
+
+// Nom du fichier de cache dans OutDir
+const scanCacheName = "scan_cache.json"
+
+// Version du format du cache : un cache d'une autre version est ignoré
+// (2 : fichiers repérés par leur chemin relatif au dossier analysé)
+const scanCacheVersion = 2
+
+// Statistiques gardées pour un fichier
+type cachedFile struct {
+	Size           int64     `json:"size"`
+	ModTime        time.Time `json:"mtime"`
+	SHA256         string    `json:"sha256"`
+	Encoding       string    `json:"encoding"`
+	Lines          int       `json:"lines"`
+	Words          int       `json:"words"`
+	WordLen        int       `json:"word_len"`
+	Lang           string    `json:"lang"`
+	LangConfidence float64   `json:"lang_confidence"`
+}
+
+// Dernier scan d'un dossier
+type cachedScan struct {
+	Time  time.Time             `json:"time"`
+	Files map[string]cachedFile `json:"files"` // clé : chemin relatif au dossier analysé, séparé par "/"
+}
+
+// Contenu de scan_cache.json
+type scanCacheData struct {
+	Version int                    `json:"version"`
+	Roots   map[string]*cachedScan `json:"roots"` // clé : chemin absolu du dossier analysé
+}
+
+// Cache utilisé pendant un scan
+type scanCache struct {
+	path       string
+	root       string
+	verifyHash bool // comparer aussi l'empreinte SHA-256 (le fichier est relu)
+	freq       bool // fréquence des mots pour le rapport HTML (recalculée, jamais gardée dans le cache)
+	rescan     bool // flag -rescan : tout réanalyser, le scan précédent ne sert qu'au rapport des changements
+	data       scanCacheData
+	prev       map[string]cachedFile // scan précédent du dossier (lecture seule pendant le scan), par clé
+	cur        map[string]cachedFile // scan en cours (rempli dans l'ordre par emit)
+	seen       map[string]bool       // fichiers rencontrés, même en erreur
+}
+
+// Charge le cache du dossier root ; rescan = ne reprendre aucune statistique (flag -rescan)
+func loadScanCache(cfg Config, root string, rescan bool) *scanCache {
+	c := &scanCache{
+		path:       filepath.Join(cfg.OutDir, scanCacheName),
+		root:       root,
+		verifyHash: cfg.ScanCacheHash,
+		freq:       cfg.HTMLReport,
+		rescan:     rescan,
+		cur:        make(map[string]cachedFile),
+		seen:       make(map[string]bool),
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		c.root = abs
+	}
+
+	if b, err := os.ReadFile(c.path); err == nil {
+		if err := json.Unmarshal(b, &c.data); err != nil || c.data.Version != scanCacheVersion {
+			fmt.Println("Cache de scan illisible ou d'une autre version, il sera reconstruit.")
+			c.data = scanCacheData{}
+		}
+	}
+	if c.data.Roots == nil {
+		c.data = scanCacheData{Version: scanCacheVersion, Roots: make(map[string]*cachedScan)}
+	}
+	if s := c.data.Roots[c.root]; s != nil {
+		c.prev = s.Files
+	}
+	return c
+}
+
+// Clé d'une entrée : chemin relatif au dossier analysé, comme la clé du dossier est son chemin absolu,
+// pour que les clés ne dépendent pas de la façon dont le dossier a été saisi
+func (c *scanCache) key(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		if rel, err := filepath.Rel(c.root, abs); err == nil {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(p)
+}
+
+// Indique si un fichier a changé depuis le scan précédent : taille + date,
+// ou taille + empreinte avec scan_cache_hash (un fichier seulement "touché" est alors inchangé)
+func (c *scanCache) changed(prev cachedFile, size int64, mtime time.Time, sum string) bool {
+	if prev.Size != size {
+		return true
+	}
+	if c.verifyHash {
+		return sum != prev.SHA256
+	}
+	return !prev.ModTime.Equal(mtime)
+}
+
+// Analyse une entrée en reprenant les statistiques du cache si elle n'a pas changé (appelé par les workers).
+// Sans scan_cache_hash, la taille et la date de l'entrée suffisent : un fichier inchangé n'est ni haché
+// ni réanalysé, il n'est lu que pour être recopié dans merged.txt. Le contenu n'est haché qu'une fois.
+func (c *scanCache) analyze(e scanEntry) fileResult {
+	prev, ok := c.prev[c.key(e.Path)]
+	ok = ok && !c.rescan
+	if ok && !c.verifyHash && !c.changed(prev, e.Size, e.ModTime, "") {
+		return c.reuse(e, prev, nil)
+	}
+	data, err := e.read()
+	if err != nil {
+		return fileResult{Entry: e, Err: err}
+	}
+	sum := sha256Hex(data)
+	if ok && c.verifyHash && !c.changed(prev, e.Size, e.ModTime, sum) {
+		return c.reuse(e, prev, data)
+	}
+	return analyzeData(e, data, sum, c.freq)
+}
+
+// Résultat d'une entrée inchangée à partir du cache ; data = contenu s'il a déjà été lu.
+// Le texte décodé n'est pas gardé dans le cache (il ferait la taille des fichiers) : l'entrée est relue
+// et décodée pour merged.txt et les fréquences du rapport HTML, seules les statistiques sont reprises.
+func (c *scanCache) reuse(e scanEntry, prev cachedFile, data []byte) fileResult {
+	if data == nil {
+		var err error
+		if data, err = e.read(); err != nil {
+			return fileResult{Entry: e, Err: err}
+		}
+	}
+	res := fileResult{
+		Entry:    e,
+		Encoding: prev.Encoding,
+		Lines:    prev.Lines,
+		Words:    prev.Words,
+		WordLen:  prev.WordLen,
+		SHA256:   prev.SHA256,
+		Lang:     langResult{Lang: prev.Lang, Confidence: prev.LangConfidence},
+		Cached:   true,
+	}
+	res.Text, _ = decodeText(data)
+	if c.freq {
+		res.Freq = wordFrequencies(res.Text, prev.Lang)
+	}
+	return res
+}
+
+// Mémorise le résultat d'un fichier pour le prochain scan (appelé dans l'ordre par emit)
+func (c *scanCache) store(r fileResult) {
+	key := c.key(r.Entry.Path)
+	c.seen[key] = true
+	if r.Err != nil {
+		return
+	}
+	c.cur[key] = cachedFile{
+		Size:           r.Entry.Size,
+		ModTime:        r.Entry.ModTime,
+		SHA256:         r.SHA256,
+		Encoding:       r.Encoding,
+		Lines:          r.Lines,
+		Words:          r.Words,
+		WordLen:        r.WordLen,
+		Lang:           r.Lang.Lang,
+		LangConfidence: r.Lang.Confidence,
+	}
+}
+
+// Écrit le cache avec le scan en cours
+func (c *scanCache) save() error {
+	c.data.Roots[c.root] = &cachedScan{Time: time.Now(), Files: c.cur}
+	b, err := json.Marshal(c.data)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, b, 0644)
+}
+
+// Nombre de fichiers par type de changement
+type scanChanges struct {
+	New, Modified, Deleted, Unchanged int
+}
+
+// Table "changes" : fichiers nouveaux, modifiés et supprimés depuis le scan précédent
+func (c *scanCache) changesTable() (reportTable, scanChanges) {
+	t := reportTable{Name: "changes", Columns: []string{"change", "path", "size", "mtime", "previous_size", "previous_mtime"}}
+	var n scanChanges
+
+	paths := make([]string, 0, len(c.seen))
+	for p := range c.seen {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		cur, ok := c.cur[p]
+		if !ok {
+			continue // fichier en erreur : ni nouveau ni modifié
+		}
+		prev, found := c.prev[p]
+		switch {
+		case !found:
+			n.New++
+			t.Rows = append(t.Rows, []any{"new", p, cur.Size, cur.ModTime, nil, nil})
+		case c.changed(prev, cur.Size, cur.ModTime, cur.SHA256):
+			n.Modified++
+			t.Rows = append(t.Rows, []any{"modified", p, cur.Size, cur.ModTime, prev.Size, prev.ModTime})
+		default:
+			n.Unchanged++
+		}
+	}
+
+	var deleted []string
+	for p := range c.prev {
+		if !c.seen[p] {
+			deleted = append(deleted, p)
+		}
+	}
+	sort.Strings(deleted)
+	for _, p := range deleted {
+		prev := c.prev[p]
+		n.Deleted++
+		t.Rows = append(t.Rows, []any{"deleted", p, nil, nil, prev.Size, prev.ModTime})
+	}
+	return t, n
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Les clés ne dépendent pas de la façon dont le dossier et le fichier sont saisis
+func TestScanCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	os.Mkdir("data", 0755)
+	abs := filepath.Join(dir, "data")
+	for _, root := range []string{"data", "./data/", abs, filepath.Join(abs, "sub", "..")} {
+		c := loadScanCache(Config{OutDir: dir}, root, false)
+		if c.root != abs {
+			t.Errorf("dossier %q : clé %q, attendu %q", root, c.root, abs)
+		}
+		for _, p := range []string{
+			filepath.Join("data", "sub", "a.txt"),
+			filepath.Join(".", "data", "sub", ".", "a.txt"),
+			filepath.Join(abs, "sub", "a.txt"),
+		} {
+			if got := c.key(p); got != "sub/a.txt" {
+				t.Errorf("dossier %q, fichier %q : clé %q, attendu sub/a.txt", root, p, got)
+			}
+		}
+		// Membre d'archive : archive/chemin/interne
+		if got := c.key(filepath.Join(abs, "a.zip", "x", "b.txt")); got != "a.zip/x/b.txt" {
+			t.Errorf("membre d'archive : clé %q", got)
+		}
+	}
+}
+
+// Scan de dir avec le cache : résultats par nom de fichier et bilan des changements
+func cachedScanDir(t *testing.T, cfg Config, dir string, rescan bool) (map[string]fileResult, scanChanges) {
+	t.Helper()
+	c := loadScanCache(cfg, dir, rescan)
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := make(map[string]fileResult)
+	for _, f := range files {
+		info, _ := f.Info()
+		r := c.analyze(fileEntry(filepath.Join(dir, f.Name()), info))
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		c.store(r)
+		res[f.Name()] = r
+	}
+	_, n := c.changesTable()
+	if err := c.save(); err != nil {
+		t.Fatal(err)
+	}
+	return res, n
+}
+
+func TestScanCacheInvalidation(t *testing.T) {
+	old := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		hash   bool
+		cached map[string]bool // fichiers repris du cache au second scan
+		n      scanChanges
+	}{
+		// Taille + date : un fichier "touché" est modifié, un contenu changé à taille et date égales passe inaperçu
+		{"date", false, map[string]bool{"same.txt": true, "content.txt": true}, scanChanges{New: 1, Modified: 2, Deleted: 1, Unchanged: 2}},
+		// Taille + empreinte : l'inverse
+		{"empreinte", true, map[string]bool{"same.txt": true, "touched.txt": true}, scanChanges{New: 1, Modified: 2, Deleted: 1, Unchanged: 2}},
+	}
+	for _, tt := range tests {
+		dir, out := t.TempDir(), t.TempDir()
+		cfg := Config{OutDir: out, ScanCacheHash: tt.hash}
+		for name, content := range map[string]string{
+			"same.txt":    "rien ne change",
+			"size.txt":    "taille",
+			"content.txt": "contenu A",
+			"touched.txt": "touché",
+			"deleted.txt": "supprimé",
+		} {
+			p := writeTestFile(t, dir, name, []byte(content))
+			os.Chtimes(p, old, old)
+		}
+		if _, n := cachedScanDir(t, cfg, dir, false); n != (scanChanges{New: 5}) {
+			t.Fatalf("%s : premier scan %+v", tt.name, n)
+		}
+
+		writeTestFile(t, dir, "size.txt", []byte("taille changée"))
+		os.Chtimes(writeTestFile(t, dir, "content.txt", []byte("contenu B")), old, old)
+		os.Chtimes(filepath.Join(dir, "touched.txt"), old.Add(time.Hour), old.Add(time.Hour))
+		os.Remove(filepath.Join(dir, "deleted.txt"))
+		writeTestFile(t, dir, "new.txt", []byte("nouveau"))
+
+		res, n := cachedScanDir(t, cfg, dir, false)
+		if n != tt.n {
+			t.Errorf("%s : changements %+v, attendu %+v", tt.name, n, tt.n)
+		}
+		for name, r := range res {
+			if r.Cached != tt.cached[name] {
+				t.Errorf("%s : %s repris du cache = %v, attendu %v", tt.name, name, r.Cached, tt.cached[name])
+			}
+		}
+		// Une entrée reprise du cache est relue pour merged.txt
+		if res["same.txt"].Text != "rien ne change" || res["same.txt"].Words != 3 {
+			t.Errorf("%s : same.txt repris : %q, %d mot(s)", tt.name, res["same.txt"].Text, res["same.txt"].Words)
+		}
+	}
+}
+
+// -rescan : tout est réanalysé, mais les changements sont comptés par rapport au scan précédent
+func TestScanCacheRescan(t *testing.T) {
+	dir, out := t.TempDir(), t.TempDir()
+	cfg := Config{OutDir: out}
+	writeTestFile(t, dir, "a.txt", []byte("alpha"))
+	writeTestFile(t, dir, "b.txt", []byte("beta"))
+	cachedScanDir(t, cfg, dir, false)
+
+	writeTestFile(t, dir, "b.txt", []byte("beta modifié"))
+	writeTestFile(t, dir, "c.txt", []byte("gamma"))
+	res, n := cachedScanDir(t, cfg, dir, true)
+	for name, r := range res {
+		if r.Cached {
+			t.Errorf("%s repris du cache malgré -rescan", name)
+		}
+	}
+	if want := (scanChanges{New: 1, Modified: 1, Unchanged: 1}); n != want {
+		t.Errorf("changements %+v, attendu %+v", n, want)
+	}
+
+	// Le cache écrit par -rescan sert au scan suivant
+	res, n = cachedScanDir(t, cfg, dir, false)
+	if !res["a.txt"].Cached || !res["b.txt"].Cached || n != (scanChanges{Unchanged: 3}) {
+		t.Errorf("après -rescan : %+v", n)
+	}
+}
+
+// Cache d'une autre version : ignoré, tous les fichiers sont nouveaux
+func TestScanCacheVersion(t *testing.T) {
+	dir, out := t.TempDir(), t.TempDir()
+	writeTestFile(t, dir, "a.txt", []byte("alpha"))
+	writeTestFile(t, out, scanCacheName, []byte(`{"version": 1, "roots": {}}`))
+	if _, n := cachedScanDir(t, Config{OutDir: out}, dir, false); n != (scanChanges{New: 1}) {
+		t.Errorf("changements %+v", n)
+	}
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ------- Comparaison de deux dossiers --------
+// Les deux arborescences sont parcourues avec les filtres du choix B, puis comparées chemin
+// par chemin : fichiers seulement dans A, seulement dans B, et fichiers différents (taille + date,
+// ou empreinte SHA-256). Pour les fichiers texte différents, un diff unifié est écrit dans OutDir.
+
+// Lignes de contexte autour de chaque bloc de différences (comme diff -u)
+const diffContext = 3
+
+// Au-delà, le diff d'un fichier est abandonné (le coût de l'algorithme croît avec le nombre de différences)
+const diffMaxEdits = 2000
+
+// Opération d'un diff ligne à ligne : ' ' ligne commune, '-' ligne de A, '+' ligne de B
+type diffOp struct {
+	Kind byte
+	A, B int // position dans A et dans B
+}
+
+// Diff ligne à ligne (algorithme de Myers) ; false si plus de maxEdits différences
+func diffLines(a, b []string, maxEdits int) ([]diffOp, bool) {
+	n, m := len(a), len(b)
+	off := n + m + 1
+	v := make([]int, 2*off+1) // v[off+k] : x atteint le plus loin sur la diagonale k
+	var trace [][]int         // trace[d] : v[-d..d] avant l'étape d
+
+	found := -1
+	for d := 0; d <= n+m && found < 0; d++ {
+		if d > maxEdits {
+			return nil, false
+		}
+		trace = append(trace, append([]int(nil), v[off-d:off+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[off+k-1] < v[off+k+1]) {
+				x = v[off+k+1] // insertion
+			} else {
+				x = v[off+k-1] + 1 // suppression
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				found = d
+				break
+			}
+		}
+	}
+
+	// On remonte le chemin depuis la fin
+	var ops []diffOp
+	x, y := n, m
+	for d := found; d > 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, diffOp{' ', x - 1, y - 1})
+			x, y = x-1, y-1
+		}
+		if x == prevX {
+			ops = append(ops, diffOp{'+', x, y - 1})
+		} else {
+			ops = append(ops, diffOp{'-', x - 1, y})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		ops = append(ops, diffOp{' ', x - 1, y - 1})
+		x, y = x-1, y-1
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops, true
+}
+
+// Début et longueur d'un bloc au format "@@ -l,s +l,s @@" (ligne précédente si le bloc est vide)
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// Diff unifié entre deux textes, "" s'ils sont identiques
+func unifiedDiff(nameA, nameB, textA, textB string, maxEdits int) (string, bool) {
+	a := splitDiffLines(textA)
+	b := splitDiffLines(textB)
+	ops, ok := diffLines(a, b, maxEdits)
+	if !ok {
+		return "", false
+	}
+
+	// Positions des lignes modifiées
+	var changes []int
+	for i, op := range ops {
+		if op.Kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return "", true
+	}
+
+	var sb strings.Builder
+	sb.WriteString("--- " + nameA + "\n")
+	sb.WriteString("+++ " + nameB + "\n")
+	for i := 0; i < len(changes); {
+		// Un bloc regroupe les changements séparés par au plus 2*diffContext lignes communes
+		j := i
+		for j+1 < len(changes) && changes[j+1]-changes[j] <= 2*diffContext+1 {
+			j++
+		}
+		start := max(changes[i]-diffContext, 0)
+		end := min(changes[j]+diffContext+1, len(ops))
+
+		countA, countB := 0, 0
+		for _, op := range ops[start:end] {
+			if op.Kind != '+' {
+				countA++
+			}
+			if op.Kind != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(ops[start].A, countA), hunkRange(ops[start].B, countB))
+		for _, op := range ops[start:end] {
+			switch op.Kind {
+			case ' ':
+				sb.WriteString(" " + a[op.A] + "\n")
+			case '-':
+				sb.WriteString("-" + a[op.A] + "\n")
+			case '+':
+				sb.WriteString("+" + b[op.B] + "\n")
+			}
+		}
+		i = j + 1
+	}
+	return sb.String(), true
+}
+
+// Lignes d'un texte (sans la ligne vide après le dernier '\n')
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// Fichier d'une des deux arborescences
+type treeFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Fichiers d'une arborescence, par chemin relatif
+func collectTree(cfg Config, root string, allNames bool) (map[string]treeFile, error) {
+	files := make(map[string]treeFile)
+	filter := newScanFilter(cfg, root)
+	filter.allNames = allNames
+	err := walkFiltered(cfg, root, filter, func(p string, info os.FileInfo) {
+		if filter.keepSize(info.Size()) {
+			files[filter.rel(p)] = treeFile{Path: p, Size: info.Size(), ModTime: info.ModTime()}
+		}
+	})
+	return files, err
+}
+
+// Indique si deux fichiers de même chemin diffèrent
+// Sans empreinte : taille ou date (à la seconde près, les copies perdent souvent les fractions)
+func filesDiffer(a, b treeFile, byHash bool) (bool, error) {
+	if a.Size != b.Size {
+		return true, nil
+	}
+	if !byHash {
+		return !a.ModTime.Truncate(time.Second).Equal(b.ModTime.Truncate(time.Second)), nil
+	}
+	ha, err := hashFile(a.Path, -1)
+	if err != nil {
+		return false, err
+	}
+	hb, err := hashFile(b.Path, -1)
+	if err != nil {
+		return false, err
+	}
+	return ha != hb, nil
+}
+
+// Texte d'un fichier pour le diff, false si le fichier est binaire
+func diffText(p string) (string, bool, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return "", false, err
+	}
+	text, enc := decodeText(data)
+	if enc != encUTF16LE && enc != encUTF16BE && bytes.IndexByte(data, 0) >= 0 {
+		return "", false, nil
+	}
+	return text, true, nil
+}
+
+// Choix F : comparaison de deux dossiers
+func choixDirDiff(cfg Config, reader *bufio.Reader) {
+	fmt.Printf("Dossier A (ENTER = %s) : ", cfg.BaseDir)
+	dirA, _ := reader.ReadString('\n')
+	dirA = strings.TrimSpace(dirA)
+	if dirA == "" {
+		dirA = cfg.BaseDir
+	}
+	fmt.Print("Dossier B (par exemple la sauvegarde) : ")
+	dirB, _ := reader.ReadString('\n')
+	dirB = strings.TrimSpace(dirB)
+	if dirB == "" {
+		fmt.Println("Dossier B manquant.")
+		return
+	}
+	allNames := !askYes(reader, "Limiter aux fichiers des filtres du choix B (extensions, include) ?")
+	byHash := askYes(reader, "Comparer le contenu (empreinte SHA-256) plutôt que taille + date ?")
+
+	filesA, err := collectTree(cfg, dirA, allNames)
+	if err != nil {
+		fmt.Println("Erreur parcours de", dirA, ":", err)
+		return
+	}
+	filesB, err := collectTree(cfg, dirB, allNames)
+	if err != nil {
+		fmt.Println("Erreur parcours de", dirB, ":", err)
+		return
+	}
+
+	// Tous les chemins relatifs, triés
+	var rels []string
+	for rel := range filesA {
+		rels = append(rels, rel)
+	}
+	for rel := range filesB {
+		if _, ok := filesA[rel]; !ok {
+			rels = append(rels, rel)
+		}
+	}
+	sort.Strings(rels)
+
+	t := reportTable{Name: "differences", Columns: []string{"status", "path", "size_a", "size_b", "mtime_a", "mtime_b"}}
+	var diffs strings.Builder
+	onlyA, onlyB, differ, same := 0, 0, 0, 0
+	for _, rel := range rels {
+		a, inA := filesA[rel]
+		b, inB := filesB[rel]
+		switch {
+		case !inB:
+			onlyA++
+			t.Rows = append(t.Rows, []any{"only_a", rel, a.Size, nil, a.ModTime, nil})
+		case !inA:
+			onlyB++
+			t.Rows = append(t.Rows, []any{"only_b", rel, nil, b.Size, nil, b.ModTime})
+		default:
+			d, err := filesDiffer(a, b, byHash)
+			if err != nil {
+				fmt.Println("Erreur lecture", rel, ":", err)
+				continue
+			}
+			if !d {
+				same++
+				continue
+			}
+			differ++
+			t.Rows = append(t.Rows, []any{"differ", rel, a.Size, b.Size, a.ModTime, b.ModTime})
+
+			// Diff unifié des fichiers texte
+			textA, okA, errA := diffText(a.Path)
+			textB, okB, errB := diffText(b.Path)
+			if errA != nil || errB != nil {
+				fmt.Println("Erreur lecture", rel)
+				continue
+			}
+			if !okA || !okB {
+				fmt.Fprintf(&diffs, "Fichiers binaires %s et %s différents\n", a.Path, b.Path)
+				continue
+			}
+			d2, ok := unifiedDiff(a.Path+"\t"+formatCell(a.ModTime), b.Path+"\t"+formatCell(b.ModTime), textA, textB, diffMaxEdits)
+			if !ok {
+				fmt.Fprintf(&diffs, "Trop de différences entre %s et %s, diff non calculé\n", a.Path, b.Path)
+			}
+			diffs.WriteString(d2)
+		}
+	}
+
+	fmt.Printf("Seulement dans A : %d, seulement dans B : %d, différents : %d, identiques : %d\n", onlyA, onlyB, differ, same)
+	for _, r := range t.Rows {
+		fmt.Printf("%-7s %s\n", r[0], r[1])
+	}
+
+	os.MkdirAll(cfg.OutDir, os.ModePerm)
+	written, err := writeReport(cfg, "dirdiff", []reportTable{t})
+	if err != nil {
+		fmt.Println("Erreur rapport :", err)
+	}
+	diffPath := filepath.Join(cfg.OutDir, "dirdiff.diff")
+	if err := writeTextFile(diffPath, diffs.String(), cfg.OutputEncoding); err != nil {
+		fmt.Println("Erreur écriture :", err)
+	} else {
+		written = append(written, diffPath)
+	}
+	for _, f := range written {
+		fmt.Println("Rapport généré :", f)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Nombre minimal de lignes supprimées + ajoutées, par la plus longue sous-suite commune
+func minEdits(a, b []string) int {
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	return len(a) + len(b) - 2*lcs[0][0]
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct{ a, b string }{
+		{"", ""},
+		{"", "a b c"},
+		{"a b c", ""},
+		{"a b c", "a b c"},
+		{"a b c", "a x c"},
+		{"a b c a b b a", "c b a b a c"}, // exemple de l'article de Myers
+		{"a b c d e f", "x a b c y e f z"},
+		{"a a a a", "a a"},
+		{"x y z", "a b c"},
+		{"1 2 3 4 5 6 7 8 9", "1 2 4 5 6 7 9 10"},
+	}
+	for _, tt := range tests {
+		a, b := strings.Fields(tt.a), strings.Fields(tt.b)
+		ops, ok := diffLines(a, b, 100)
+		if !ok {
+			t.Fatalf("diffLines(%q, %q) abandonné", tt.a, tt.b)
+		}
+
+		// Les opérations doivent redonner A (lignes ' ' et '-') et B (lignes ' ' et '+')
+		var gotA, gotB []string
+		edits := 0
+		for _, op := range ops {
+			switch op.Kind {
+			case ' ':
+				if a[op.A] != b[op.B] {
+					t.Errorf("%q -> %q : ligne commune différente %q / %q", tt.a, tt.b, a[op.A], b[op.B])
+				}
+				gotA, gotB = append(gotA, a[op.A]), append(gotB, b[op.B])
+			case '-':
+				gotA = append(gotA, a[op.A])
+				edits++
+			case '+':
+				gotB = append(gotB, b[op.B])
+				edits++
+			}
+		}
+		if strings.Join(gotA, " ") != tt.a || strings.Join(gotB, " ") != tt.b {
+			t.Errorf("%q -> %q : le diff redonne %q -> %q", tt.a, tt.b, strings.Join(gotA, " "), strings.Join(gotB, " "))
+		}
+		if want := minEdits(a, b); edits != want {
+			t.Errorf("%q -> %q : %d changement(s), minimum %d", tt.a, tt.b, edits, want)
+		}
+	}
+}
+
+func TestDiffLinesMaxEdits(t *testing.T) {
+	a, b := strings.Fields("a b c d"), strings.Fields("w x y z")
+	if _, ok := diffLines(a, b, 7); ok {
+		t.Error("diff de 8 changements accepté avec maxEdits = 7")
+	}
+	if _, ok := diffLines(a, b, 8); !ok {
+		t.Error("diff de 8 changements refusé avec maxEdits = 8")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name, a, b, want string
+	}{
+		{"identiques", "a\nb\n", "a\nb\n", ""},
+		{"ajout dans un fichier vide", "", "a\nb\n", "--- A\n+++ B\n@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"tout supprimé", "a\n", "", "--- A\n+++ B\n@@ -1 +0,0 @@\n-a\n"},
+		{
+			"modification au milieu",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			"1\n2\n3\n4\nCINQ\n6\n7\n8\n9\n",
+			"--- A\n+++ B\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+CINQ\n 6\n 7\n 8\n",
+		},
+		{
+			"deux blocs séparés",
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			"0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			"--- A\n+++ B\n@@ -1,3 +1,4 @@\n+0\n 1\n 2\n 3\n@@ -9,4 +10,3 @@\n 9\n 10\n 11\n-12\n",
+		},
+	}
+	for _, tt := range tests {
+		got, ok := unifiedDiff("A", "B", tt.a, tt.b, diffMaxEdits)
+		if !ok {
+			t.Fatalf("%s : diff abandonné", tt.name)
+		}
+		if got != tt.want {
+			t.Errorf("%s :\n%s\nattendu :\n%s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ------- Occupation disque (du) --------
+// Taille et nombre de fichiers cumulés par dossier et par extension, plus gros fichiers
+// et dossiers, et arbre des dossiers trié par taille avec le pourcentage du total.
+
+// Dossier de l'arbre, avec la taille cumulée de tout ce qu'il contient
+type duNode struct {
+	Path     string
+	Size     int64
+	Files    int
+	children map[string]*duNode
+}
+
+// Sous-dossier name, créé si besoin
+func (n *duNode) child(name string) *duNode {
+	if n.children == nil {
+		n.children = make(map[string]*duNode)
+	}
+	c := n.children[name]
+	if c == nil {
+		c = &duNode{Path: filepath.Join(n.Path, name)}
+		n.children[name] = c
+	}
+	return c
+}
+
+// Sous-dossiers triés par taille décroissante (à égalité, par nom)
+func (n *duNode) sorted() []*duNode {
+	list := make([]*duNode, 0, len(n.children))
+	for _, c := range n.children {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Size != list[j].Size {
+			return list[i].Size > list[j].Size
+		}
+		return list[i].Path < list[j].Path
+	})
+	return list
+}
+
+// Tous les dossiers de l'arbre (parcours en profondeur, par taille)
+func (n *duNode) all() []*duNode {
+	list := []*duNode{n}
+	for _, c := range n.sorted() {
+		list = append(list, c.all()...)
+	}
+	return list
+}
+
+// Fichier compté
+type duFile struct {
+	Path string
+	Size int64
+}
+
+// Totaux d'une extension
+type duExt struct {
+	Ext   string
+	Size  int64
+	Files int
+}
+
+// Résultat du parcours
+type diskUsage struct {
+	Root  *duNode
+	Files []duFile
+	Exts  map[string]*duExt
+}
+
+// Ajoute un fichier (rel : chemin relatif à la racine, séparé par "/")
+func (u *diskUsage) add(p, rel string, size int64) {
+	u.Files = append(u.Files, duFile{Path: p, Size: size})
+
+	// Taille cumulée sur la racine et sur chaque dossier parent
+	n := u.Root
+	n.Size += size
+	n.Files++
+	if dir := path.Dir(rel); dir != "." {
+		for _, part := range strings.Split(dir, "/") {
+			n = n.child(part)
+			n.Size += size
+			n.Files++
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(p))
+	if ext == "" {
+		ext = "(sans extension)"
+	}
+	e := u.Exts[ext]
+	if e == nil {
+		e = &duExt{Ext: ext}
+		u.Exts[ext] = e
+	}
+	e.Size += size
+	e.Files++
+}
+
+// Pourcentage du total
+func (u *diskUsage) percent(size int64) float64 {
+	if u.Root.Size == 0 {
+		return 0
+	}
+	return float64(size) * 100 / float64(u.Root.Size)
+}
+
+// Tables du rapport : dossiers, extensions, plus gros fichiers et dossiers
+func (u *diskUsage) tables(top int) []reportTable {
+	dirs := reportTable{Name: "directories", Columns: []string{"path", "size", "files", "percent"}}
+	for _, n := range u.Root.all() {
+		dirs.Rows = append(dirs.Rows, []any{n.Path, n.Size, n.Files, u.percent(n.Size)})
+	}
+
+	exts := make([]*duExt, 0, len(u.Exts))
+	for _, e := range u.Exts {
+		exts = append(exts, e)
+	}
+	sort.Slice(exts, func(i, j int) bool {
+		if exts[i].Size != exts[j].Size {
+			return exts[i].Size > exts[j].Size
+		}
+		return exts[i].Ext < exts[j].Ext
+	})
+	et := reportTable{Name: "extensions", Columns: []string{"extension", "size", "files", "percent"}}
+	for _, e := range exts {
+		et.Rows = append(et.Rows, []any{e.Ext, e.Size, e.Files, u.percent(e.Size)})
+	}
+
+	files := append([]duFile(nil), u.Files...)
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Size != files[j].Size {
+			return files[i].Size > files[j].Size
+		}
+		return files[i].Path < files[j].Path
+	})
+	if len(files) > top {
+		files = files[:top]
+	}
+	tf := reportTable{Name: "top_files", Columns: []string{"rank", "path", "size", "percent"}}
+	for i, f := range files {
+		tf.Rows = append(tf.Rows, []any{i + 1, f.Path, f.Size, u.percent(f.Size)})
+	}
+
+	// Plus gros dossiers, hors racine (qui fait toujours 100 %)
+	nodes := u.Root.all()[1:]
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Size > nodes[j].Size })
+	if len(nodes) > top {
+		nodes = nodes[:top]
+	}
+	td := reportTable{Name: "top_directories", Columns: []string{"rank", "path", "size", "files", "percent"}}
+	for i, n := range nodes {
+		td.Rows = append(td.Rows, []any{i + 1, n.Path, n.Size, n.Files, u.percent(n.Size)})
+	}
+	return []reportTable{dirs, et, tf, td}
+}
+
+// Arbre des dossiers jusqu'à maxDepth niveaux sous la racine (0 = illimité)
+func (u *diskUsage) tree(maxDepth int) string {
+	var labels []string
+	var nodes []*duNode
+	var walk func(n *duNode, prefix string, depth int)
+	walk = func(n *duNode, prefix string, depth int) {
+		if maxDepth > 0 && depth >= maxDepth {
+			return
+		}
+		children := n.sorted()
+		for i, c := range children {
+			branch, next := "├── ", "│   "
+			if i == len(children)-1 {
+				branch, next = "└── ", "    "
+			}
+			labels = append(labels, prefix+branch+filepath.Base(c.Path))
+			nodes = append(nodes, c)
+			walk(c, prefix+next, depth+1)
+		}
+	}
+	labels = append(labels, u.Root.Path)
+	nodes = append(nodes, u.Root)
+	walk(u.Root, "", 0)
+
+	width := 0
+	for _, l := range labels {
+		if n := utf8.RuneCountInString(l); n > width {
+			width = n
+		}
+	}
+	var sb strings.Builder
+	for i, l := range labels {
+		n := nodes[i]
+		fmt.Fprintf(&sb, "%s%s  %10s  %6.1f %%  %d fichier(s)\n",
+			l, strings.Repeat(" ", width-utf8.RuneCountInString(l)), formatSize(n.Size), u.percent(n.Size), n.Files)
+	}
+	return sb.String()
+}
+
+// Choix F : occupation disque d'un dossier
+func choixDiskUsage(cfg Config, reader *bufio.Reader) {
+	dir := askPath(reader, cfg.BaseDir)
+	filter := newScanFilter(cfg, dir)
+	filter.allNames = !askYes(reader, "Limiter aux fichiers des filtres du choix B (extensions, include) ?")
+
+	fmt.Print("Nombre de fichiers et dossiers dans les classements (ENTER = 10) : ")
+	s, _ := reader.ReadString('\n')
+	top, err := parseCount(s, 10)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print("Profondeur de l'arbre (ENTER = 3, 0 = illimitée) : ")
+	s, _ = reader.ReadString('\n')
+	depth, err := parseCount(s, 3)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	// Un fichier lié plusieurs fois (liens physiques) n'est compté qu'une fois, comme du
+	u := &diskUsage{Root: &duNode{Path: filepath.Clean(dir)}, Exts: make(map[string]*duExt)}
+	seen := make(map[fileID]bool)
+	err = walkFiltered(cfg, dir, filter, func(p string, info os.FileInfo) {
+		if !filter.keepSize(info.Size()) {
+			return
+		}
+		if id, ok := getFileID(p, info); ok {
+			if seen[id] {
+				return
+			}
+			seen[id] = true
+		}
+		u.add(p, filter.rel(p), info.Size())
+	})
+	if err != nil {
+		fmt.Println("Erreur parcours :", err)
+		return
+	}
+
+	tree := u.tree(depth)
+	fmt.Println()
+	fmt.Print(tree)
+	fmt.Printf("\nTotal : %s (%d octets) dans %d fichier(s)\n", formatSize(u.Root.Size), u.Root.Size, u.Root.Files)
+
+	tables := u.tables(top)
+	fmt.Println("\nPlus gros fichiers :")
+	for _, r := range tables[2].Rows {
+		fmt.Printf("%3d. %10s  %s\n", r[0], formatSize(r[2].(int64)), r[1])
+	}
+	fmt.Println("\nPar extension :")
+	for _, r := range tables[1].Rows {
+		fmt.Printf("%-18s %10s  %6.1f %%  %d fichier(s)\n", r[0], formatSize(r[1].(int64)), r[3], r[2])
+	}
+
+	os.MkdirAll(cfg.OutDir, os.ModePerm)
+	written, err := writeReport(cfg, "du", tables)
+	if err != nil {
+		fmt.Println("Erreur rapport :", err)
+	}
+	treePath := filepath.Join(cfg.OutDir, "du_tree.txt")
+	if err := writeTextFile(treePath, tree, cfg.OutputEncoding); err != nil {
+		fmt.Println("Erreur écriture :", err)
+	} else {
+		written = append(written, treePath)
+	}
+	for _, f := range written {
+		fmt.Println("Rapport généré :", f)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Arborescence de test, sans passer par le disque
+func sampleUsage() *diskUsage {
+	u := &diskUsage{Root: &duNode{Path: "racine"}, Exts: make(map[string]*duExt)}
+	for _, f := range []struct {
+		rel  string
+		size int64
+	}{
+		{"a.txt", 100},
+		{"docs/b.md", 300},
+		{"docs/img/c.PNG", 500},
+		{"src/d.go", 50},
+		{"Makefile", 50},
+	} {
+		u.add(filepath.Join("racine", filepath.FromSlash(f.rel)), f.rel, f.size)
+	}
+	return u
+}
+
+func TestDiskUsageTotals(t *testing.T) {
+	u := sampleUsage()
+	tests := []struct {
+		path  string
+		size  int64
+		files int
+	}{
+		{"racine", 1000, 5},
+		{filepath.Join("racine", "docs"), 800, 2},
+		{filepath.Join("racine", "docs", "img"), 500, 1},
+		{filepath.Join("racine", "src"), 50, 1},
+	}
+	nodes := u.Root.all()
+	if len(nodes) != len(tests) {
+		t.Fatalf("%d dossier(s), attendu %d", len(nodes), len(tests))
+	}
+	// Parcours en profondeur, par taille décroissante
+	for i, n := range nodes {
+		if n.Path != tests[i].path || n.Size != tests[i].size || n.Files != tests[i].files {
+			t.Errorf("dossier %d : %s %d o %d fichier(s), attendu %s %d o %d", i, n.Path, n.Size, n.Files, tests[i].path, tests[i].size, tests[i].files)
+		}
+	}
+	if p := u.percent(250); p != 25 {
+		t.Errorf("percent(250) = %v, attendu 25", p)
+	}
+	if p := (&diskUsage{Root: &duNode{}}).percent(10); p != 0 {
+		t.Errorf("dossier vide : percent = %v", p)
+	}
+}
+
+func TestDiskUsageTables(t *testing.T) {
+	tables := sampleUsage().tables(2)
+	want := map[string][]string{
+		// Extensions en minuscules, à égalité de taille par ordre alphabétique
+		"extensions":      {".png", ".md", ".txt", "(sans extension)", ".go"},
+		"top_files":       {filepath.Join("racine", "docs", "img", "c.PNG"), filepath.Join("racine", "docs", "b.md")},
+		"top_directories": {filepath.Join("racine", "docs"), filepath.Join("racine", "docs", "img")},
+	}
+	for _, tab := range tables[1:] {
+		col := 1
+		if tab.Name == "extensions" {
+			col = 0
+		}
+		var got []string
+		for _, r := range tab.Rows {
+			got = append(got, r[col].(string))
+		}
+		if strings.Join(got, "|") != strings.Join(want[tab.Name], "|") {
+			t.Errorf("%s : %v, attendu %v", tab.Name, got, want[tab.Name])
+		}
+	}
+	if r := tables[1].Rows[0]; r[1] != int64(500) || r[2] != 1 || r[3] != 50.0 {
+		t.Errorf("extension .png : %v", r)
+	}
+}
+
+func TestDiskUsageTree(t *testing.T) {
+	u := sampleUsage()
+	tests := []struct {
+		depth int
+		want  string
+	}{
+		{0, "" +
+			"racine           1000 o   100.0 %  5 fichier(s)\n" +
+			"├── docs          800 o    80.0 %  2 fichier(s)\n" +
+			"│   └── img       500 o    50.0 %  1 fichier(s)\n" +
+			"└── src            50 o     5.0 %  1 fichier(s)\n"},
+		{1, "" +
+			"racine        1000 o   100.0 %  5 fichier(s)\n" +
+			"├── docs       800 o    80.0 %  2 fichier(s)\n" +
+			"└── src         50 o     5.0 %  1 fichier(s)\n"},
+	}
+	for _, tt := range tests {
+		if got := u.tree(tt.depth); got != tt.want {
+			t.Errorf("profondeur %d :\n%s\nattendu :\n%s", tt.depth, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ------- Recherche de doublons --------
+// Les fichiers sont regroupés par taille, puis par empreinte des premiers octets,
+// puis par empreinte complète : seuls les fichiers de même taille sont lus, et seuls ceux
+// dont le début est identique sont lus en entier.
+
+// Nombre d'octets lus pour l'empreinte partielle
+const dupPartialSize = 4096
+
+// Fichier candidat
+type dupFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Groupe de fichiers identiques ; le premier est gardé, les autres sont des doublons
+type dupSet struct {
+	SHA256 string
+	Size   int64
+	Files  []dupFile
+}
+
+// Octets perdus par le groupe
+func (s dupSet) wasted() int64 {
+	return s.Size * int64(len(s.Files)-1)
+}
+
+// Empreinte SHA-256 des limit premiers octets du fichier (limit < 0 : tout le fichier)
+func hashFile(p string, limit int64) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if limit >= 0 {
+		r = io.LimitReader(f, limit)
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Regroupe les fichiers par empreinte, les groupes d'un seul fichier sont écartés
+func groupByHash(files []dupFile, limit int64) map[string][]dupFile {
+	groups := make(map[string][]dupFile)
+	for _, f := range files {
+		sum, err := hashFile(f.Path, limit)
+		if err != nil {
+			fmt.Println("Erreur lecture", f.Path, ":", err)
+			continue
+		}
+		groups[sum] = append(groups[sum], f)
+	}
+	for sum, g := range groups {
+		if len(g) < 2 {
+			delete(groups, sum)
+		}
+	}
+	return groups
+}
+
+// Cherche les doublons parmi les fichiers (les fichiers vides sont ignorés)
+func findDuplicates(files []dupFile) []dupSet {
+	bySize := make(map[int64][]dupFile)
+	for _, f := range files {
+		if f.Size > 0 {
+			bySize[f.Size] = append(bySize[f.Size], f)
+		}
+	}
+
+	var sets []dupSet
+	for size, group := range bySize {
+		if len(group) < 2 {
+			continue
+		}
+		// Empreinte partielle inutile si le fichier tient dans les premiers octets
+		candidates := [][]dupFile{group}
+		if size > dupPartialSize {
+			candidates = nil
+			for _, g := range groupByHash(group, dupPartialSize) {
+				candidates = append(candidates, g)
+			}
+		}
+		for _, c := range candidates {
+			for sum, g := range groupByHash(c, -1) {
+				sort.Slice(g, func(i, j int) bool { return g[i].Path < g[j].Path })
+				sets = append(sets, dupSet{SHA256: sum, Size: size, Files: g})
+			}
+		}
+	}
+
+	// Les groupes qui font perdre le plus de place d'abord
+	sort.Slice(sets, func(i, j int) bool {
+		if sets[i].wasted() != sets[j].wasted() {
+			return sets[i].wasted() > sets[j].wasted()
+		}
+		return sets[i].Files[0].Path < sets[j].Files[0].Path
+	})
+	return sets
+}
+
+// Tables "sets" (un groupe par ligne) et "duplicates" (un fichier par ligne)
+func duplicateTables(sets []dupSet) []reportTable {
+	st := reportTable{Name: "sets", Columns: []string{"set", "sha256", "size", "files", "wasted"}}
+	ft := reportTable{Name: "duplicates", Columns: []string{"set", "path", "size", "mtime", "keep"}}
+	for i, s := range sets {
+		st.Rows = append(st.Rows, []any{i + 1, s.SHA256, s.Size, len(s.Files), s.wasted()})
+		for j, f := range s.Files {
+			ft.Rows = append(ft.Rows, []any{i + 1, f.Path, f.Size, f.ModTime, j == 0})
+		}
+	}
+	return []reportTable{st, ft}
+}
+
+// Vérifie juste avant l'action que le doublon est toujours identique au fichier gardé
+func stillDuplicate(keep, dup string, s dupSet) error {
+	for _, p := range []string{keep, dup} {
+		info, err := os.Stat(p)
+		if err != nil {
+			return err
+		}
+		if info.Size() != s.Size {
+			return fmt.Errorf("%s a changé de taille", p)
+		}
+		sum, err := hashFile(p, -1)
+		if err != nil {
+			return err
+		}
+		if sum != s.SHA256 {
+			return fmt.Errorf("%s a changé de contenu", p)
+		}
+	}
+	return nil
+}
+
+// Remplace dup par un lien physique vers keep (lien créé à côté puis renommé)
+func replaceByHardlink(keep, dup string) error {
+	tmp := dup + ".fileops-link"
+	os.Remove(tmp)
+	if err := os.Link(keep, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, dup); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Choix F : recherche de doublons dans un dossier
+func choixDuplicates(cfg Config, reader *bufio.Reader) {
+	dir := askPath(reader, cfg.BaseDir)
+	filter := newScanFilter(cfg, dir)
+	filter.allNames = !askYes(reader, "Limiter aux fichiers des filtres du choix B (extensions, include) ?")
+
+	// Les fichiers qui sont déjà des liens physiques les uns des autres ne comptent qu'une fois
+	var files []dupFile
+	seen := make(map[fileID]bool)
+	err := walkFiltered(cfg, dir, filter, func(path string, info os.FileInfo) {
+		if !info.Mode().IsRegular() || !filter.keepSize(info.Size()) {
+			return
+		}
+		if id, ok := getFileID(path, info); ok {
+			if seen[id] {
+				return
+			}
+			seen[id] = true
+		}
+		files = append(files, dupFile{Path: path, Size: info.Size(), ModTime: info.ModTime()})
+	})
+	if err != nil {
+		fmt.Println("Erreur parcours :", err)
+		return
+	}
+
+	sets := findDuplicates(files)
+	var wasted int64
+	dups := 0
+	for _, s := range sets {
+		wasted += s.wasted()
+		dups += len(s.Files) - 1
+	}
+	fmt.Println("Fichiers examinés :", len(files))
+	fmt.Printf("Groupes de doublons : %d, doublons : %d, place perdue : %s (%d octets)\n",
+		len(sets), dups, formatSize(wasted), wasted)
+	for i, s := range sets {
+		if i == 10 {
+			fmt.Println("... (voir le rapport)")
+			break
+		}
+		fmt.Printf("%d) %s x %d, %s perdus\n", i+1, formatSize(s.Size), len(s.Files), formatSize(s.wasted()))
+		for j, f := range s.Files {
+			mark := "  "
+			if j == 0 {
+				mark = "* "
+			}
+			fmt.Println("   " + mark + f.Path)
+		}
+	}
+
+	os.MkdirAll(cfg.OutDir, os.ModePerm)
+	written, err := writeReport(cfg, "duplicates", duplicateTables(sets))
+	if err != nil {
+		fmt.Println("Erreur rapport :", err)
+	}
+	for _, f := range written {
+		fmt.Println("Rapport généré :", f)
+	}
+	if len(sets) == 0 {
+		return
+	}
+
+	// Actions : le premier fichier de chaque groupe (*) est gardé
+	fmt.Println("\nAction sur les doublons (le fichier marqué * est gardé) :")
+	fmt.Println("1 - Aucune")
+	fmt.Println("2 - Remplacer les doublons par des liens physiques")
+	fmt.Println("3 - Supprimer les doublons")
+	fmt.Print("Choix : ")
+	action, _ := reader.ReadString('\n')
+	action = strings.TrimSpace(action)
+	if action != "2" && action != "3" {
+		return
+	}
+	verb := "remplacer par des liens physiques"
+	if action == "3" {
+		verb = "SUPPRIMER"
+	}
+	if !askYes(reader, fmt.Sprintf("Confirmer : %s %d fichier(s) ?", verb, dups)) {
+		fmt.Println("Annulé.")
+		return
+	}
+
+	done := 0
+	for _, s := range sets {
+		keep := s.Files[0].Path
+		for _, f := range s.Files[1:] {
+			if err := stillDuplicate(keep, f.Path, s); err != nil {
+				fmt.Println("Ignoré :", f.Path, ":", err)
+				continue
+			}
+			if action == "2" {
+				err = replaceByHardlink(keep, f.Path)
+			} else {
+				err = os.Remove(f.Path)
+			}
+			if err != nil {
+				fmt.Println("Erreur :", f.Path, ":", err)
+				continue
+			}
+			if action == "2" {
+				logAction(cfg.OutDir, "DEDUP LINK "+f.Path+" -> "+keep)
+			} else {
+				logAction(cfg.OutDir, "DEDUP DELETE "+f.Path+" (doublon de "+keep+")")
+			}
+			done++
+		}
+	}
+	fmt.Printf("%d doublon(s) traité(s), actions enregistrées dans audit.log\n", done)
+}
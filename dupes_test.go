@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Fichiers de test et leur dupFile
+func dupFiles(t *testing.T, dir string, files map[string]string) []dupFile {
+	t.Helper()
+	var list []dupFile
+	for name, content := range files {
+		p := writeTestFile(t, dir, name, []byte(content))
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list = append(list, dupFile{Path: p, Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return list
+}
+
+func TestFindDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	big := strings.Repeat("x", 3*dupPartialSize)
+	files := dupFiles(t, dir, map[string]string{
+		"a.txt":      "bonjour",
+		"b.txt":      "bonjour",
+		"c.txt":      "bonsoir", // même taille, contenu différent
+		"seul.txt":   "taille unique",
+		"vide1.txt":  "",
+		"vide2.txt":  "", // fichiers vides ignorés
+		"gros1.bin":  big,
+		"gros2.bin":  big,
+		"gros3.bin":  big,
+		"fin.bin":    big[:len(big)-1] + "y", // même début que les gros : écarté par l'empreinte complète
+		"debut.bin":  "y" + big[1:],          // écarté dès l'empreinte partielle
+		"autre1.bin": strings.Repeat("z", dupPartialSize+1),
+		"autre2.bin": strings.Repeat("z", dupPartialSize+1),
+	})
+	sets := findDuplicates(files)
+
+	// Groupes triés par place perdue décroissante
+	want := []struct {
+		files  string
+		wasted int64
+	}{
+		{"gros1.bin gros2.bin gros3.bin", int64(2 * len(big))},
+		{"autre1.bin autre2.bin", dupPartialSize + 1},
+		{"a.txt b.txt", 7},
+	}
+	if len(sets) != len(want) {
+		t.Fatalf("%d groupe(s), attendu %d : %+v", len(sets), len(want), sets)
+	}
+	for i, s := range sets {
+		var names []string
+		for _, f := range s.Files {
+			names = append(names, filepath.Base(f.Path))
+		}
+		if got := strings.Join(names, " "); got != want[i].files || s.wasted() != want[i].wasted {
+			t.Errorf("groupe %d : %s (%d o perdus), attendu %s (%d o)", i, got, s.wasted(), want[i].files, want[i].wasted)
+		}
+		if sum, _ := hashFile(s.Files[0].Path, -1); sum != s.SHA256 {
+			t.Errorf("groupe %d : empreinte %s, attendu %s", i, s.SHA256, sum)
+		}
+	}
+
+	tables := duplicateTables(sets)
+	if len(tables[0].Rows) != 3 || len(tables[1].Rows) != 7 || tables[1].Rows[0][4] != true || tables[1].Rows[1][4] != false {
+		t.Errorf("tables : %v", tables)
+	}
+}
+
+func TestReplaceByHardlink(t *testing.T) {
+	dir := t.TempDir()
+	files := dupFiles(t, dir, map[string]string{"a.txt": "bonjour", "b.txt": "bonjour"})
+	sets := findDuplicates(files)
+	if len(sets) != 1 {
+		t.Fatalf("%d groupe(s)", len(sets))
+	}
+	keep, dup := sets[0].Files[0].Path, sets[0].Files[1].Path
+	if err := stillDuplicate(keep, dup, sets[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := replaceByHardlink(keep, dup); err != nil {
+		t.Skipf("liens physiques indisponibles : %v", err)
+	}
+	a, _ := os.Stat(keep)
+	b, _ := os.Stat(dup)
+	if !os.SameFile(a, b) {
+		t.Error("le doublon n'est pas un lien vers le fichier gardé")
+	}
+	if _, err := os.Stat(dup + ".fileops-link"); !os.IsNotExist(err) {
+		t.Error("lien temporaire resté sur le disque")
+	}
+}
+
+// Un fichier modifié entre la recherche et l'action n'est plus un doublon
+func TestStillDuplicate(t *testing.T) {
+	dir := t.TempDir()
+	files := dupFiles(t, dir, map[string]string{"a.txt": "bonjour", "b.txt": "bonjour"})
+	s := findDuplicates(files)[0]
+	keep, dup := s.Files[0].Path, s.Files[1].Path
+
+	writeTestFile(t, dir, "b.txt", []byte("bonsoir"))
+	if err := stillDuplicate(keep, dup, s); err == nil {
+		t.Error("contenu changé : erreur attendue")
+	}
+	writeTestFile(t, dir, "b.txt", []byte("bonjour !"))
+	if err := stillDuplicate(keep, dup, s); err == nil {
+		t.Error("taille changée : erreur attendue")
+	}
+	os.Remove(dup)
+	if err := stillDuplicate(keep, dup, s); err == nil {
+		t.Error("fichier supprimé : erreur attendue")
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := map[int64]string{0: "0 o", 1023: "1023 o", 1024: "1.0 Ko", 1536: "1.5 Ko", 5 << 20: "5.0 Mo", 3 << 30: "3.0 Go", 2 << 40: "2.0 To"}
+	for n, want := range tests {
+		if got := formatSize(n); got != want {
+			t.Errorf("formatSize(%d) = %q, attendu %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ------- Encodages de caractères --------
+// Détection (BOM puis heuristiques) et conversion vers/depuis UTF-8.
+// Les analyses travaillent toujours en UTF-8, les fichiers de sortie peuvent
+// être écrits dans un autre encodage (clé output_encoding ou flag -encoding).
+
+// Encodages reconnus
+const (
+	encUTF8    = "utf-8"
+	encUTF8BOM = "utf-8-bom"
+	encUTF16LE = "utf-16le"
+	encUTF16BE = "utf-16be"
+	encWin1252 = "windows-1252"
+	encLatin1  = "iso-8859-1"
+)
+
+// BOM (Byte Order Mark) de chaque encodage
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Caractères de la plage 0x80-0x9F en Windows-1252 (les 5 octets non définis restent tels quels)
+var win1252High = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// Normalise un nom d'encodage saisi par l'utilisateur ("latin1", "cp1252", "UTF8"...)
+// Retourne "" si l'encodage n'est pas supporté
+func normalizeEncoding(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "utf8", "utf-8":
+		return encUTF8
+	case "utf8bom", "utf-8-bom", "utf-8-sig":
+		return encUTF8BOM
+	case "utf16", "utf-16", "utf16le", "utf-16le":
+		return encUTF16LE
+	case "utf16be", "utf-16be":
+		return encUTF16BE
+	case "cp1252", "windows-1252", "win1252":
+		return encWin1252
+	case "latin1", "latin-1", "iso-8859-1", "iso8859-1":
+		return encLatin1
+	}
+	return ""
+}
+
+// Détecte l'encodage d'un contenu brut et la taille du BOM à ignorer
+func detectEncoding(data []byte) (string, int) {
+	// 1) BOM
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return encUTF8BOM, len(bomUTF8)
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return encUTF16LE, len(bomUTF16LE)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return encUTF16BE, len(bomUTF16BE)
+	}
+
+	// 2) UTF-16 sans BOM : beaucoup d'octets nuls, tous du même côté
+	sample := data
+	if len(sample) > 4096 {
+		sample = sample[:4096]
+	}
+	evenZero, oddZero := 0, 0
+	for i, b := range sample {
+		if b == 0 {
+			if i%2 == 0 {
+				evenZero++
+			} else {
+				oddZero++
+			}
+		}
+	}
+	half := len(sample) / 2
+	if half > 0 {
+		if oddZero > half*3/10 && evenZero < half/10 {
+			return encUTF16LE, 0
+		}
+		if evenZero > half*3/10 && oddZero < half/10 {
+			return encUTF16BE, 0
+		}
+	}
+
+	// 3) UTF-8 valide (l'ASCII pur est aussi de l'UTF-8)
+	if utf8.Valid(data) {
+		return encUTF8, 0
+	}
+
+	// 4) Sinon encodage 8 bits : Windows-1252 si la plage 0x80-0x9F est utilisée, Latin-1 sinon
+	for _, b := range data {
+		if b >= 0x80 && b <= 0x9F {
+			return encWin1252, 0
+		}
+	}
+	return encLatin1, 0
+}
+
+// Décode un contenu brut en UTF-8, retourne le texte et l'encodage détecté
+func decodeText(data []byte) (string, string) {
+	enc, bom := detectEncoding(data)
+	return decodeBytes(data[bom:], enc), enc
+}
+
+// Décode un contenu (sans BOM) dont l'encodage est connu
+func decodeBytes(data []byte, enc string) string {
+	switch enc {
+	case encUTF16LE, encUTF16BE:
+		units := make([]uint16, len(data)/2)
+		for i := range units {
+			if enc == encUTF16LE {
+				units[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+			} else {
+				units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+			}
+		}
+		return string(utf16.Decode(units))
+	case encWin1252, encLatin1:
+		var sb strings.Builder
+		sb.Grow(len(data))
+		for _, b := range data {
+			if enc == encWin1252 && b >= 0x80 && b <= 0x9F {
+				sb.WriteRune(win1252High[b-0x80])
+			} else {
+				sb.WriteRune(rune(b))
+			}
+		}
+		return sb.String()
+	}
+	return string(data)
+}
+
+// Encode un texte UTF-8 dans l'encodage demandé (sans BOM)
+// Les caractères non représentables en 8 bits sont remplacés par '?'
+func encodeText(s, enc string) []byte {
+	switch enc {
+	case encUTF16LE, encUTF16BE:
+		units := utf16.Encode([]rune(s))
+		out := make([]byte, 0, len(units)*2)
+		for _, u := range units {
+			if enc == encUTF16LE {
+				out = append(out, byte(u), byte(u>>8))
+			} else {
+				out = append(out, byte(u>>8), byte(u))
+			}
+		}
+		return out
+	case encWin1252, encLatin1:
+		out := make([]byte, 0, len(s))
+		for _, r := range s {
+			out = append(out, encodeByte(r, enc))
+		}
+		return out
+	}
+	return []byte(s)
+}
+
+// Convertit une rune en octet Windows-1252 ou Latin-1
+func encodeByte(r rune, enc string) byte {
+	if r < 0x80 || (r >= 0xA0 && r <= 0xFF) {
+		return byte(r)
+	}
+	if r <= 0x9F && enc == encLatin1 {
+		return byte(r)
+	}
+	if enc == encWin1252 {
+		for i, c := range win1252High {
+			if c == r {
+				return byte(0x80 + i)
+			}
+		}
+	}
+	return '?'
+}
+
+// BOM à écrire en tête d'un fichier de sortie
+func encodingBOM(enc string) []byte {
+	switch enc {
+	case encUTF8BOM:
+		return bomUTF8
+	case encUTF16LE:
+		return bomUTF16LE
+	case encUTF16BE:
+		return bomUTF16BE
+	}
+	return nil
+}
+
+// Fichier de sortie qui convertit à la volée le texte UTF-8 vers l'encodage choisi
+type textOutput struct {
+	f   *os.File
+	enc string
+}
+
+// Crée un fichier de sortie et écrit le BOM si l'encodage en a un
+func createTextOutput(path, enc string) (*textOutput, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if bom := encodingBOM(enc); bom != nil {
+		if _, err := f.Write(bom); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return &textOutput{f: f, enc: enc}, nil
+}
+
+// Écrit une chaîne UTF-8 dans le fichier en la convertissant
+func (t *textOutput) WriteString(s string) (int, error) {
+	return t.f.Write(encodeText(s, t.enc))
+}
+
+// Ferme le fichier de sortie
+func (t *textOutput) Close() error {
+	return t.f.Close()
+}
+
+// Écrit un fichier texte complet dans l'encodage choisi
+func writeTextFile(path, s, enc string) error {
+	out, err := createTextOutput(path, enc)
+	if err != nil {
+		return err
+	}
+	if _, err := out.WriteString(s); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Vérifie l'encodage de sortie de la config, UTF-8 si inconnu
+func checkOutputEncoding(name string) string {
+	enc := normalizeEncoding(name)
+	if enc == "" {
+		fmt.Println("Encodage de sortie inconnu :", name, "- utf-8 utilisé.")
+		return encUTF8
+	}
+	return enc
+}
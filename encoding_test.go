@@ -0,0 +1,78 @@
+package main
+
+import "testing"
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		enc  string
+		bom  int
+	}{
+		{"vide", nil, encUTF8, 0},
+		{"ascii", []byte("hello"), encUTF8, 0},
+		{"utf-8", []byte("déjà vu"), encUTF8, 0},
+		{"utf-8 avec BOM", []byte("\xEF\xBB\xBFété"), encUTF8BOM, 3},
+		{"utf-16le avec BOM", []byte("\xFF\xFEa\x00b\x00"), encUTF16LE, 2},
+		{"utf-16be avec BOM", []byte("\xFE\xFF\x00a\x00b"), encUTF16BE, 2},
+		{"utf-16le sans BOM", encodeText("bonjour le monde", encUTF16LE), encUTF16LE, 0},
+		{"utf-16be sans BOM", encodeText("bonjour le monde", encUTF16BE), encUTF16BE, 0},
+		{"windows-1252", []byte("\x93guillemets\x94 \x80"), encWin1252, 0},
+		{"latin-1", []byte("caf\xE9 cr\xE8me"), encLatin1, 0},
+	}
+	for _, tt := range tests {
+		enc, bom := detectEncoding(tt.data)
+		if enc != tt.enc || bom != tt.bom {
+			t.Errorf("%s : %s (BOM %d), attendu %s (BOM %d)", tt.name, enc, bom, tt.enc, tt.bom)
+		}
+	}
+}
+
+func TestDecodeText(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"utf-8 avec BOM", []byte("\xEF\xBB\xBFété"), "été"},
+		{"utf-16le", []byte("\xFF\xFEc\x00a\x00f\x00\xE9\x00"), "café"},
+		{"utf-16be", []byte("\xFE\xFF\x00c\x00a\x00f\x00\xE9"), "café"},
+		{"windows-1252", []byte("\x93oui\x94 \x80"), "“oui” €"},
+		{"latin-1", []byte("caf\xE9"), "café"},
+	}
+	for _, tt := range tests {
+		if got, _ := decodeText(tt.data); got != tt.want {
+			t.Errorf("%s : %q, attendu %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Un texte encodé puis décodé doit revenir à l'identique
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	text := "Ligne 1 : déjà, “€”\nLigne 2 : Œuvre\n"
+	for _, enc := range []string{encUTF8, encUTF8BOM, encUTF16LE, encUTF16BE, encWin1252} {
+		data := append(encodingBOM(enc), encodeText(text, enc)...)
+		got, detected := decodeText(data)
+		if got != text {
+			t.Errorf("%s : %q, attendu %q (détecté %s)", enc, got, text, detected)
+		}
+	}
+}
+
+func TestNormalizeEncoding(t *testing.T) {
+	tests := map[string]string{
+		"":          encUTF8,
+		"UTF8":      encUTF8,
+		"utf-8-sig": encUTF8BOM,
+		"UTF-16":    encUTF16LE,
+		"utf16be":   encUTF16BE,
+		"cp1252":    encWin1252,
+		" Latin1 ":  encLatin1,
+		"ebcdic":    "",
+	}
+	for in, want := range tests {
+		if got := normalizeEncoding(in); got != want {
+			t.Errorf("normalizeEncoding(%q) = %q, attendu %q", in, got, want)
+		}
+	}
+}
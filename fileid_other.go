@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package main
+
+import "os"
+
+// Pas d'identifiant de fichier disponible : la détection de boucle est désactivée
+func getFileID(p string, info os.FileInfo) (fileID, bool) {
+	return fileID{}, false
+}
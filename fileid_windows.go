@@ -0,0 +1,33 @@
+//go:build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// Identifiant numéro de série du volume + index du fichier (les dossiers doivent
+// être ouverts avec FILE_FLAG_BACKUP_SEMANTICS)
+func getFileID(p string, info os.FileInfo) (fileID, bool) {
+	ptr, err := syscall.UTF16PtrFromString(p)
+	if err != nil {
+		return fileID{}, false
+	}
+	h, err := syscall.CreateFile(ptr, 0,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_FLAG_BACKUP_SEMANTICS, 0)
+	if err != nil {
+		return fileID{}, false
+	}
+	defer syscall.CloseHandle(h)
+
+	var data syscall.ByHandleFileInformation
+	if err := syscall.GetFileInformationByHandle(h, &data); err != nil {
+		return fileID{}, false
+	}
+	return fileID{
+		dev: uint64(data.VolumeSerialNumber),
+		ino: uint64(data.FileIndexHigh)<<32 | uint64(data.FileIndexLow),
+	}, true
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ------- Filtres de l'analyse multi-fichiers --------
+// Extensions multiples, motifs include / exclude (**/*.log, !vendor/**), profondeur maximale,
+// bornes de taille, fichiers cachés et prise en compte des .gitignore / .ignore.
+
+// Motif glob sur un chemin relatif séparé par "/" : "*" et "?" dans un segment, "**" pour
+// zéro ou plusieurs dossiers. Un motif sans "/" est comparé au seul nom du fichier.
+func globMatch(pattern, p string) bool {
+	pattern = strings.TrimPrefix(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(p))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(p, "/"))
+}
+
+// Comparaison segment par segment avec gestion de "**"
+func matchSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			// "**" absorbe 0, 1, 2... segments
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pat[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], segs[0]); !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// Règle d'un fichier .gitignore / .ignore
+type ignoreRule struct {
+	base     string // dossier du fichier d'ignore, relatif à la racine ("" = racine)
+	pattern  string
+	negate   bool // "!motif" : ré-inclut
+	dirOnly  bool // "motif/" : seulement les dossiers
+	anchored bool // motif contenant un "/" : relatif au dossier du fichier d'ignore
+}
+
+// Lit les règles d'un fichier .gitignore / .ignore
+func loadIgnoreFile(file, base string) []ignoreRule {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var rules []ignoreRule
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			r.negate, line = true, line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			r.dirOnly, line = true, strings.TrimSuffix(line, "/")
+		}
+		r.anchored = strings.Contains(line, "/")
+		r.pattern = strings.TrimPrefix(line, "/")
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// Indique si la règle s'applique au chemin (relatif à la racine)
+func (r ignoreRule) match(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		if !strings.HasPrefix(rel, r.base+"/") {
+			return false
+		}
+		rel = strings.TrimPrefix(rel, r.base+"/")
+	}
+	if r.anchored {
+		return matchSegments(strings.Split(r.pattern, "/"), strings.Split(rel, "/"))
+	}
+	ok, _ := path.Match(r.pattern, path.Base(rel))
+	return ok
+}
+
+// Filtre construit à partir de la config pour un parcours de dossier
+type scanFilter struct {
+	root       string
+	extensions []string
+	include    []string
+	exclude    []string
+	maxDepth   int
+	minSize    int64
+	maxSize    int64
+	hidden     bool
+	gitignore  bool
+	allNames   bool                    // tous les noms : ni extensions ni motifs include
+	archives   bool                    // archives parcourues comme des dossiers : le nom est filtré sur leurs membres
+	ignores    map[string][]ignoreRule // règles d'ignore par dossier relatif
+}
+
+// Crée le filtre du choix B
+func newScanFilter(cfg Config, root string) *scanFilter {
+	f := &scanFilter{
+		root:      root,
+		maxDepth:  cfg.MaxDepth,
+		minSize:   cfg.MinSize,
+		maxSize:   cfg.MaxSize,
+		hidden:    cfg.IncludeHidden,
+		gitignore: cfg.UseGitignore,
+		ignores:   make(map[string][]ignoreRule),
+	}
+
+	// Extensions : liste "extensions" ou à défaut l'extension par défaut
+	exts := cfg.Extensions
+	if len(exts) == 0 {
+		exts = []string{cfg.DefaultExt}
+	}
+	for _, e := range exts {
+		if e = strings.ToLower(strings.TrimSpace(e)); e != "" {
+			if !strings.HasPrefix(e, ".") {
+				e = "." + e
+			}
+			f.extensions = append(f.extensions, e)
+		}
+	}
+
+	// Motifs : "!motif" dans include équivaut à une exclusion
+	for _, p := range cfg.Include {
+		if strings.HasPrefix(p, "!") {
+			f.exclude = append(f.exclude, p[1:])
+		} else if p != "" {
+			f.include = append(f.include, p)
+		}
+	}
+	for _, p := range cfg.Exclude {
+		if p != "" {
+			f.exclude = append(f.exclude, strings.TrimPrefix(p, "!"))
+		}
+	}
+	return f
+}
+
+// Chemin relatif à la racine, séparé par "/"
+func (f *scanFilter) rel(p string) string {
+	r, err := filepath.Rel(f.root, p)
+	if err != nil {
+		return filepath.ToSlash(p)
+	}
+	return filepath.ToSlash(r)
+}
+
+// Indique si le chemin est exclu par un motif exclude ou un fichier d'ignore
+func (f *scanFilter) excluded(rel string, isDir bool) bool {
+	for _, p := range f.exclude {
+		if globMatch(p, rel) {
+			return true
+		}
+	}
+	if !f.gitignore {
+		return false
+	}
+
+	// Règles des dossiers parents, de la racine vers le dossier du fichier : la dernière qui s'applique gagne
+	var dirs []string
+	for d := path.Dir(rel); ; d = path.Dir(d) {
+		if d == "." {
+			dirs = append(dirs, "")
+			break
+		}
+		dirs = append(dirs, d)
+	}
+	ignored := false
+	for i := len(dirs) - 1; i >= 0; i-- {
+		for _, r := range f.ignores[dirs[i]] {
+			if r.match(rel, isDir) {
+				ignored = !r.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// Appelé pour chaque dossier : false = ne pas descendre dedans
+func (f *scanFilter) enterDir(p string, d fs.DirEntry) bool {
+	rel := f.rel(p)
+	if rel != "." {
+		if !f.hidden && isHidden(p, d) {
+			return false
+		}
+		// Profondeur : max_depth = 1 ne garde que les fichiers de la racine
+		if f.maxDepth > 0 && strings.Count(rel, "/")+1 >= f.maxDepth {
+			return false
+		}
+		if f.excluded(rel, true) {
+			return false
+		}
+	}
+
+	// Chargement des fichiers d'ignore du dossier
+	if f.gitignore {
+		base := rel
+		if base == "." {
+			base = ""
+		}
+		for _, name := range []string{".gitignore", ".ignore"} {
+			f.ignores[base] = append(f.ignores[base], loadIgnoreFile(filepath.Join(p, name), base)...)
+		}
+	}
+	return true
+}
+
+// Indique si un fichier du disque doit être analysé (ou parcouru, pour une archive) et rend ses
+// informations. Elles ne sont lues qu'après les filtres sur le nom, pour les seuls fichiers candidats.
+func (f *scanFilter) keepFile(p string, d fs.DirEntry) (os.FileInfo, bool) {
+	if !f.hidden && isHidden(p, d) {
+		return nil, false
+	}
+	rel := f.rel(p)
+	if f.excluded(rel, false) {
+		return nil, false
+	}
+	// Archive parcourue : le nom et la taille sont vérifiés sur chaque membre (keepMember),
+	// la taille de l'archive ne dit rien de celle de ses membres
+	archive := f.archives && isArchive(p)
+	if !archive && !f.keepName(rel) {
+		return nil, false
+	}
+	info, err := d.Info()
+	if err != nil || (!archive && !f.keepSize(info.Size())) {
+		return nil, false
+	}
+	return info, true
+}
+
+// Filtre sur le nom (extensions puis motifs include), aussi utilisé pour les membres d'archives
+// Si des motifs include sont donnés, ils remplacent le filtre sur les extensions
+func (f *scanFilter) keepName(rel string) bool {
+	if f.allNames {
+		return true
+	}
+	if len(f.include) > 0 {
+		for _, p := range f.include {
+			if globMatch(p, rel) || globMatch(p, stripCompressionExt(rel)) {
+				return true
+			}
+		}
+		return false
+	}
+	name := strings.ToLower(stripCompressionExt(rel))
+	for _, e := range f.extensions {
+		if strings.HasSuffix(name, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bornes de taille (0 = pas de borne)
+func (f *scanFilter) keepSize(size int64) bool {
+	if f.minSize > 0 && size < f.minSize {
+		return false
+	}
+	if f.maxSize > 0 && size > f.maxSize {
+		return false
+	}
+	return true
+}
+
+// Filtre appliqué aux membres d'une archive : chemin de l'archive + chemin interne, et taille du membre
+func (f *scanFilter) keepMember(archivePath string) func(name string, size int64) bool {
+	return func(name string, size int64) bool {
+		rel := f.rel(archivePath) + "/" + strings.TrimPrefix(path.Clean(name), "/")
+		if !f.hidden && strings.HasPrefix(path.Base(name), ".") {
+			return false
+		}
+		return !f.excluded(rel, false) && f.keepName(rel) && f.keepSize(size)
+	}
+}
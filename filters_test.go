@@ -0,0 +1,175 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"*.log", "app.log", true},
+		{"*.log", "logs/app.log", true}, // sans "/", seul le nom compte
+		{"*.log", "app.txt", false},
+		{"a?.txt", "ab.txt", true},
+		{"a?.txt", "abc.txt", false},
+		{"logs/*.log", "logs/app.log", true},
+		{"logs/*.log", "logs/sub/app.log", false},
+		{"/logs/*.log", "logs/app.log", true},
+		{"**/*.log", "app.log", true},
+		{"**/*.log", "a/b/c/app.log", true},
+		{"vendor/**", "vendor/x/y.go", true},
+		{"vendor/**", "src/vendor/y.go", false},
+		{"src/**/test/*.go", "src/test/a.go", true},
+		{"src/**/test/*.go", "src/a/b/test/a.go", true},
+		{"src/**/test/*.go", "src/a/b/tests/a.go", false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, attendu %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIgnoreRuleMatch(t *testing.T) {
+	tests := []struct {
+		rule  ignoreRule
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{ignoreRule{pattern: "*.tmp"}, "a/b/x.tmp", false, true},
+		{ignoreRule{pattern: "*.tmp"}, "a/b/x.txt", false, false},
+		{ignoreRule{pattern: "build", dirOnly: true}, "build", true, true},
+		{ignoreRule{pattern: "build", dirOnly: true}, "build", false, false},
+		{ignoreRule{pattern: "build", dirOnly: true}, "sub/build", true, true},
+		{ignoreRule{pattern: "docs/*.md", anchored: true}, "docs/a.md", false, true},
+		{ignoreRule{pattern: "docs/*.md", anchored: true}, "sub/docs/a.md", false, false},
+		{ignoreRule{base: "sub", pattern: "*.log"}, "sub/x/a.log", false, true},
+		{ignoreRule{base: "sub", pattern: "*.log"}, "other/a.log", false, false},
+		{ignoreRule{base: "sub", pattern: "out/*", anchored: true}, "sub/out/a", false, true},
+		{ignoreRule{base: "sub", pattern: "out/*", anchored: true}, "out/a", false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.match(tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("%+v.match(%q, %v) = %v, attendu %v", tt.rule, tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestLoadIgnoreFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".gitignore")
+	content := "# commentaire\n\n*.log\r\n!keep.log\nbuild/\n/docs/*.md\n"
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got := loadIgnoreFile(file, "sub")
+	want := []ignoreRule{
+		{base: "sub", pattern: "*.log"},
+		{base: "sub", pattern: "keep.log", negate: true},
+		{base: "sub", pattern: "build", dirOnly: true},
+		{base: "sub", pattern: "docs/*.md", anchored: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d règle(s), attendu %d : %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("règle %d = %+v, attendu %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// La dernière règle qui s'applique gagne, et les règles d'un sous-dossier passent après celles de la racine
+func TestScanFilterExcluded(t *testing.T) {
+	f := &scanFilter{
+		gitignore: true,
+		exclude:   []string{"vendor/**"},
+		ignores: map[string][]ignoreRule{
+			"":    {{pattern: "*.log"}, {pattern: "keep.log", negate: true}},
+			"sub": {{base: "sub", pattern: "keep.log"}},
+		},
+	}
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"a.txt", false},
+		{"a.log", true},
+		{"keep.log", false},
+		{"x/keep.log", false},
+		{"sub/keep.log", true},
+		{"sub/a.log", true},
+		{"vendor/lib/a.txt", true},
+	}
+	for _, tt := range tests {
+		if got := f.excluded(tt.rel, false); got != tt.want {
+			t.Errorf("excluded(%q) = %v, attendu %v", tt.rel, got, tt.want)
+		}
+	}
+}
+
+// Une archive parcourue ne passe ni le filtre sur le nom ni celui sur la taille : ils s'appliquent
+// à ses membres. Une archive non parcourue est un fichier comme un autre.
+func TestKeepFileArchive(t *testing.T) {
+	dir := t.TempDir()
+	for name, size := range map[string]int{"a.txt": 10, "a.log": 10, "big.txt": 1000, "small.zip": 10, "big.zip": 1000} {
+		if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f := newScanFilter(Config{Extensions: []string{".txt"}, MaxSize: 100}, dir)
+	tests := []struct {
+		name     string
+		archives bool
+		want     bool
+	}{
+		{"a.txt", false, true},
+		{"a.log", false, false},
+		{"big.txt", false, false},
+		{"small.zip", false, false},
+		{"small.zip", true, true},
+		{"big.zip", true, true},
+	}
+	for _, tt := range tests {
+		p := filepath.Join(dir, tt.name)
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.archives = tt.archives
+		if _, got := f.keepFile(p, fs.FileInfoToDirEntry(info)); got != tt.want {
+			t.Errorf("keepFile(%s, archives=%v) = %v, attendu %v", tt.name, tt.archives, got, tt.want)
+		}
+	}
+}
+
+// Membres d'une archive : mêmes filtres que les fichiers du disque, taille du membre comprise
+func TestKeepMember(t *testing.T) {
+	dir := t.TempDir()
+	f := newScanFilter(Config{Extensions: []string{".txt"}, MinSize: 5, MaxSize: 100, Exclude: []string{"**/tmp/**"}}, dir)
+	keep := f.keepMember(filepath.Join(dir, "a.zip"))
+	tests := []struct {
+		name string
+		size int64
+		want bool
+	}{
+		{"a.txt", 10, true},
+		{"sub/a.txt", 10, true},
+		{"a.log", 10, false},
+		{"big.txt", 10 << 20, false}, // archive de 50 Ko, membre de 10 Mo
+		{"vide.txt", 0, false},
+		{".cache.txt", 10, false},
+		{"tmp/a.txt", 10, false},
+		{"a.txt.gz", 10, true},
+	}
+	for _, tt := range tests {
+		if got := keep(tt.name, tt.size); got != tt.want {
+			t.Errorf("keepMember(%s, %d) = %v, attendu %v", tt.name, tt.size, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ------- Head / Tail --------
+// head/tail par lignes ou par octets, et mode "tail -f" qui suit le fichier
+// (par scrutation régulière, ce qui fonctionne sous Windows comme sous Unix).
+
+// Lit un nombre de lignes / d'octets saisi par l'utilisateur (vide = def)
+func parseCount(s string, def int) (int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("nombre invalide : %q", s)
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("le nombre doit être positif : %d", n)
+	}
+	return n, nil
+}
+
+// Les n premières et n dernières lignes
+func headTailLines(lines []string, n int) ([]string, []string, error) {
+	if n < 0 {
+		return nil, nil, fmt.Errorf("le nombre doit être positif : %d", n)
+	}
+	if n > len(lines) {
+		n = len(lines)
+	}
+	return lines[:n], lines[len(lines)-n:], nil
+}
+
+// Les n premiers et n derniers octets du texte (UTF-8), sans couper un caractère
+func headTailBytes(text string, n int) (string, string, error) {
+	if n < 0 {
+		return "", "", fmt.Errorf("le nombre doit être positif : %d", n)
+	}
+	if n > len(text) {
+		n = len(text)
+	}
+
+	// On recule la fin du head / avance le début du tail jusqu'à une frontière de caractère
+	h := n
+	for h > 0 && h < len(text) && !utf8.RuneStart(text[h]) {
+		h--
+	}
+	t := len(text) - n
+	for t < len(text) && !utf8.RuneStart(text[t]) {
+		t++
+	}
+	return text[:h], text[t:], nil
+}
+
+// Suit un fichier comme "tail -f" jusqu'à ce que l'utilisateur appuie sur ENTRÉE
+// Gère la troncature (taille qui diminue) et la rotation (fichier remplacé)
+func followFile(path string, reader *bufio.Reader, interval time.Duration) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	// On part de la fin du fichier, l'encodage est celui du début du fichier
+	enc, _ := fileEncoding(f)
+	offset := info.Size()
+
+	// Arrêt quand l'utilisateur appuie sur ENTRÉE. Pendant le suivi, seule cette goroutine lit
+	// le menu : on ne rend jamais la main avant qu'elle ait fini, sinon elle avalerait la saisie suivante.
+	stop := make(chan struct{})
+	go func() {
+		reader.ReadString('\n')
+		close(stop)
+	}()
+
+	fmt.Println("Suivi de", path, "(ENTRÉE pour arrêter)")
+	var pending []byte
+	partial := ""
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			// Dernière ligne sans retour à la ligne
+			if rest := partial + decodeBytes(pending, enc); rest != "" {
+				fmt.Println(rest)
+			}
+			return nil
+		case <-ticker.C:
+		}
+
+		// Rotation : le chemin désigne un autre fichier que celui ouvert
+		cur, err := os.Stat(path)
+		if err != nil {
+			continue // fichier momentanément absent pendant la rotation
+		}
+		if !os.SameFile(info, cur) {
+			fmt.Println("--- fichier remplacé (rotation), réouverture ---")
+			nf, err := os.Open(path)
+			if err != nil {
+				continue
+			}
+			f.Close()
+			f, info = nf, cur
+			enc, offset = fileEncoding(f)
+			pending, partial = nil, ""
+		}
+
+		// Troncature : le fichier est plus petit que la position lue
+		if cur.Size() < offset {
+			fmt.Println("--- fichier tronqué, reprise au début ---")
+			enc, offset = fileEncoding(f)
+			pending, partial = nil, ""
+		}
+		if cur.Size() == offset {
+			continue
+		}
+
+		// Lecture des nouveaux octets
+		buf := make([]byte, cur.Size()-offset)
+		n, err := f.ReadAt(buf, offset)
+		if err != nil && err != io.EOF {
+			fmt.Println("Lecture impossible, suivi arrêté (ENTRÉE pour revenir au menu)")
+			<-stop
+			return err
+		}
+		offset += int64(n)
+		pending = append(pending, buf[:n]...)
+
+		// On ne décode que des séquences complètes (unités de 2 octets en UTF-16, lignes sinon)
+		cut := len(pending)
+		if enc == encUTF16LE || enc == encUTF16BE {
+			cut -= cut % 2
+		} else {
+			cut = bytes.LastIndexByte(pending, '\n') + 1
+		}
+		if cut == 0 {
+			continue
+		}
+		partial += decodeBytes(pending[:cut], enc)
+		pending = append([]byte(nil), pending[cut:]...)
+
+		// Affichage des lignes complètes
+		for {
+			i := strings.IndexByte(partial, '\n')
+			if i < 0 {
+				break
+			}
+			fmt.Println(strings.TrimRight(partial[:i], "\r"))
+			partial = partial[i+1:]
+		}
+	}
+}
+
+// Encodage d'un fichier ouvert d'après son début, et position après le BOM
+func fileEncoding(f *os.File) (string, int64) {
+	head := make([]byte, 4096)
+	n, _ := f.ReadAt(head, 0)
+	enc, bom := detectEncoding(head[:n])
+	return enc, int64(bom)
+}
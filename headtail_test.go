@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCount(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{"", 10, false},
+		{"  \n", 10, false},
+		{"5\n", 5, false},
+		{"0", 0, false},
+		{"-3", 0, true},
+		{"abc", 0, true},
+		{"2.5", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseCount(tt.in, 10)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseCount(%q) = %d, %v ; attendu %d, erreur %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestHeadTailLines(t *testing.T) {
+	lines := strings.Fields("a b c d e")
+	tests := []struct {
+		n          int
+		head, tail string
+	}{
+		{0, "", ""},
+		{2, "a b", "d e"},
+		{5, "a b c d e", "a b c d e"},
+		{9, "a b c d e", "a b c d e"},
+	}
+	for _, tt := range tests {
+		h, tl, err := headTailLines(lines, tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(h, " ") != tt.head || strings.Join(tl, " ") != tt.tail {
+			t.Errorf("n=%d : %v / %v, attendu %q / %q", tt.n, h, tl, tt.head, tt.tail)
+		}
+	}
+	if _, _, err := headTailLines(lines, -1); err == nil {
+		t.Error("n négatif accepté")
+	}
+}
+
+// Un caractère multi-octets n'est jamais coupé : le head recule, le tail avance
+func TestHeadTailBytes(t *testing.T) {
+	text := "été-ça" // é et ç = 2 octets : 9 octets en tout
+	tests := []struct {
+		n          int
+		head, tail string
+	}{
+		{0, "", ""},
+		{1, "", "a"},
+		{2, "é", "a"},
+		{3, "ét", "ça"},
+		{4, "ét", "-ça"},
+		{8, "été-ç", "té-ça"},
+		{9, text, text},
+		{20, text, text},
+	}
+	for _, tt := range tests {
+		h, tl, err := headTailBytes(text, tt.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h != tt.head || tl != tt.tail {
+			t.Errorf("n=%d : %q / %q, attendu %q / %q", tt.n, h, tl, tt.head, tt.tail)
+		}
+	}
+	if _, _, err := headTailBytes(text, -1); err == nil {
+		t.Error("n négatif accepté")
+	}
+}
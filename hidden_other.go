@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// Fichier caché : nom commençant par un point
+func isHidden(p string, d fs.DirEntry) bool {
+	return strings.HasPrefix(filepath.Base(p), ".")
+}
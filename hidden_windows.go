@@ -0,0 +1,26 @@
+//go:build windows
+
+package main
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// Fichier caché : nom commençant par un point ou attribut "caché" de Windows
+func isHidden(p string, d fs.DirEntry) bool {
+	if strings.HasPrefix(filepath.Base(p), ".") {
+		return true
+	}
+	// Sous Windows, Info ne relit pas le disque : les attributs viennent de la lecture du dossier
+	info, err := d.Info()
+	if err != nil {
+		return false
+	}
+	if attrs, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return attrs.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+	}
+	return false
+}
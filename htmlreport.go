@@ -0,0 +1,153 @@
+package main
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ------- Rapport HTML --------
+// Une seule page statique (CSS intégré, aucune ressource externe) avec un tableau récapitulatif,
+// les mots les plus fréquents en graphique SVG et le détail de chaque fichier dépliable.
+
+// Nombre de mots affichés dans les graphiques
+const htmlTopWords = 15
+
+// Une barre du graphique SVG
+type svgBar struct {
+	Label string
+	Count int
+	Y     int
+	Width int
+}
+
+// Graphique en barres horizontales
+type svgChart struct {
+	Height int
+	Bars   []svgBar
+}
+
+// Détail d'un fichier dans le rapport
+type htmlFile struct {
+	Record fileRecord
+	Chart  svgChart
+}
+
+// Contenu du rapport HTML
+type htmlReport struct {
+	Title     string
+	Generated string
+	Summary   [][2]string // lignes "libellé : valeur" du récapitulatif
+	Chart     svgChart    // mots les plus fréquents de l'ensemble
+	Files     []htmlFile
+}
+
+// Construit le graphique des mots les plus fréquents
+func wordChart(words []wordCount) svgChart {
+	const barHeight, maxWidth = 22, 360
+	c := svgChart{Height: len(words)*barHeight + 4}
+	max := 1
+	for _, w := range words {
+		if w.Count > max {
+			max = w.Count
+		}
+	}
+	for i, w := range words {
+		c.Bars = append(c.Bars, svgBar{
+			Label: w.Word,
+			Count: w.Count,
+			Y:     i * barHeight,
+			Width: w.Count*maxWidth/max + 1,
+		})
+	}
+	return c
+}
+
+// Détail d'un fichier : ses stats et ses mots les plus fréquents
+func newHTMLFile(rec fileRecord, freq map[string]int) htmlFile {
+	return htmlFile{Record: rec, Chart: wordChart(topWords(freq, htmlTopWords))}
+}
+
+// Écrit le rapport dans OutDir/name
+func writeHTMLReport(cfg Config, name string, r htmlReport) (string, error) {
+	r.Generated = time.Now().Format("2006-01-02 15:04:05")
+	p := filepath.Join(cfg.OutDir, name)
+	f, err := os.Create(p)
+	if err != nil {
+		return "", err
+	}
+	if err := htmlTemplate.Execute(f, r); err != nil {
+		f.Close()
+		return p, err
+	}
+	return p, f.Close()
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"pct":  func(v float64) string { return formatCell(v*100) + " %" },
+	"date": func(t time.Time) string { return formatCell(t) },
+}).Parse(`<!DOCTYPE html>
+<html lang="fr">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Segoe UI, Helvetica, Arial, sans-serif; margin: 2em; color: #222; background: #fafafa; }
+h1 { font-size: 1.6em; margin-bottom: 0.2em; }
+h2 { font-size: 1.2em; margin-top: 1.5em; border-bottom: 1px solid #ccc; }
+.meta { color: #777; font-size: 0.9em; }
+table { border-collapse: collapse; margin: 0.5em 0; background: #fff; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; font-size: 0.9em; }
+th { background: #eef; }
+td.num { text-align: right; }
+details { margin: 0.4em 0; background: #fff; border: 1px solid #ddd; padding: 0.4em 0.8em; }
+summary { cursor: pointer; font-weight: bold; }
+.err { color: #b00; }
+svg text { font-size: 12px; font-family: inherit; }
+svg rect { fill: #4a7bd0; }
+code { font-size: 0.85em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p class="meta">Généré le {{.Generated}}</p>
+
+<h2>Récapitulatif</h2>
+<table>
+{{range .Summary}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+
+{{if .Chart.Bars}}<h2>Mots les plus fréquents</h2>
+{{template "chart" .Chart}}{{end}}
+
+{{if .Files}}<h2>Fichiers</h2>
+<table>
+<tr><th>Chemin</th><th>Taille</th><th>Modifié</th><th>Lignes</th><th>Mots</th><th>Long. moy.</th><th>Encodage</th><th>Langue</th></tr>
+{{range .Files}}<tr><td>{{.Record.Path}}</td><td class="num">{{.Record.Size}}</td><td>{{date .Record.ModTime}}</td><td class="num">{{.Record.Lines}}</td><td class="num">{{.Record.Words}}</td><td class="num">{{printf "%.2f" .Record.AvgWordLen}}</td><td>{{.Record.Encoding}}</td><td>{{.Record.Language}}</td></tr>
+{{end}}</table>
+
+<h2>Détail par fichier</h2>
+{{range .Files}}<details>
+<summary>{{.Record.Path}}</summary>
+{{if .Record.Error}}<p class="err">Erreur : {{.Record.Error}}</p>{{else}}<table>
+<tr><th>Taille</th><td>{{.Record.Size}} octets</td></tr>
+<tr><th>Modifié</th><td>{{date .Record.ModTime}}</td></tr>
+<tr><th>Lignes</th><td>{{.Record.Lines}}</td></tr>
+<tr><th>Mots</th><td>{{.Record.Words}}</td></tr>
+<tr><th>Longueur moyenne</th><td>{{printf "%.2f" .Record.AvgWordLen}}</td></tr>
+<tr><th>Encodage</th><td>{{.Record.Encoding}}</td></tr>
+<tr><th>Langue</th><td>{{.Record.Language}}{{if .Record.LangConfidence}} ({{pct .Record.LangConfidence}}){{end}}</td></tr>
+{{if .Record.SHA256}}<tr><th>SHA-256</th><td><code>{{.Record.SHA256}}</code></td></tr>{{end}}
+</table>
+{{if .Chart.Bars}}{{template "chart" .Chart}}{{end}}{{end}}
+</details>
+{{end}}{{end}}
+</body>
+</html>
+{{define "chart"}}<svg xmlns="http://www.w3.org/2000/svg" width="560" height="{{.Height}}" role="img">
+{{range .Bars}}<text x="0" y="{{.Y}}" dy="15">{{.Label}}</text>
+<rect x="140" y="{{.Y}}" width="{{.Width}}" height="18" rx="2"></rect>
+<text x="{{.Width}}" y="{{.Y}}" dx="146" dy="15">{{.Count}}</text>
+{{end}}</svg>
+{{end}}`))
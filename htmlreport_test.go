@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWordChart(t *testing.T) {
+	c := wordChart([]wordCount{{"chat", 40}, {"chien", 20}, {"oiseau", 1}})
+	if c.Height != 3*22+4 || len(c.Bars) != 3 {
+		t.Fatalf("hauteur %d, %d barre(s)", c.Height, len(c.Bars))
+	}
+	want := []svgBar{
+		{"chat", 40, 0, 361},   // mot le plus fréquent : largeur maximale
+		{"chien", 20, 22, 181}, // moitié
+		{"oiseau", 1, 44, 10},
+	}
+	for i, b := range c.Bars {
+		if b != want[i] {
+			t.Errorf("barre %d : %+v, attendu %+v", i, b, want[i])
+		}
+	}
+
+	// Aucun mot : pas de barre ; mots à 0 occurrence : barres d'un pixel
+	if c := wordChart(nil); c.Height != 4 || c.Bars != nil {
+		t.Errorf("graphique vide : %+v", c)
+	}
+	if c := wordChart([]wordCount{{"a", 0}}); c.Bars[0].Width != 1 {
+		t.Errorf("barre à 0 : largeur %d", c.Bars[0].Width)
+	}
+}
+
+// Les mots du détail d'un fichier sont triés par fréquence puis par ordre alphabétique, limités à htmlTopWords
+func TestNewHTMLFile(t *testing.T) {
+	freq := map[string]int{"b": 5, "a": 5, "c": 9}
+	for i := range htmlTopWords {
+		freq[strings.Repeat("z", i+2)] = 1
+	}
+	f := newHTMLFile(fileRecord{Path: "a.txt"}, freq)
+	if len(f.Chart.Bars) != htmlTopWords {
+		t.Fatalf("%d barre(s), attendu %d", len(f.Chart.Bars), htmlTopWords)
+	}
+	var got []string
+	for _, b := range f.Chart.Bars[:3] {
+		got = append(got, b.Label)
+	}
+	if strings.Join(got, " ") != "c a b" {
+		t.Errorf("ordre des barres %v", got)
+	}
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	dir := t.TempDir()
+	r := htmlReport{
+		Title:   "Analyse <test>",
+		Summary: [][2]string{{"Fichiers", "1"}},
+		Chart:   wordChart([]wordCount{{"<script>", 3}}),
+		Files:   []htmlFile{{Record: fileRecord{Path: "a&b.txt", Size: 12, LangConfidence: 0.5}}},
+	}
+	p, err := writeHTMLReport(Config{OutDir: dir}, "report.html", r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(data)
+	for _, want := range []string{"Analyse &lt;test&gt;", "&lt;script&gt;", `width="361"`, "a&amp;b.txt", "50.00 %"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("%q absent du rapport", want)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("libellé non échappé")
+	}
+
+	if _, err := writeHTMLReport(Config{OutDir: filepath.Join(dir, "absent")}, "report.html", r); err == nil {
+		t.Error("dossier de sortie absent : erreur attendue")
+	}
+}
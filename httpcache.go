@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ------- Cache HTTP --------
+// Les réponses 200 sont gardées dans OutDir/http_cache (un fichier .json de métadonnées et un fichier
+// .body par adresse). Une copie plus récente que http_cache_ttl_min est utilisée telle quelle ; au-delà,
+// elle est revalidée par une requête conditionnelle (If-None-Match / If-Modified-Since) : une réponse
+// 304 évite de tout retélécharger. Hors ligne, seules les copies du cache sont utilisées.
+
+// Dossier du cache dans OutDir
+const httpCacheDir = "http_cache"
+
+// Adresse absente du cache en mode hors ligne
+var errOffline = errors.New("hors ligne, adresse absente du cache")
+
+// Métadonnées d'une réponse gardée
+type cachedResponse struct {
+	URL          string    `json:"url"`
+	Status       string    `json:"status"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	BodySHA256   string    `json:"body_sha256"` // empreinte du .body : un couple dépareillé n'est pas utilisé
+	Fetched      time.Time `json:"fetched"`     // dernier téléchargement ou dernière revalidation
+}
+
+// Cache d'un dossier
+type httpCache struct {
+	dir     string
+	ttl     time.Duration
+	offline bool
+}
+
+func newHTTPCache(cfg Config) *httpCache {
+	return &httpCache{
+		dir:     filepath.Join(cfg.OutDir, httpCacheDir),
+		ttl:     time.Duration(cfg.HTTPCacheTTLMin) * time.Minute,
+		offline: cfg.Offline,
+	}
+}
+
+// Chemins des deux fichiers d'une adresse
+func (c *httpCache) paths(u string) (meta, body string) {
+	base := filepath.Join(c.dir, sha256Hex([]byte(u)))
+	return base + ".json", base + ".body"
+}
+
+// Copie gardée d'une adresse
+func (c *httpCache) load(u string) (*cachedResponse, []byte, bool) {
+	metaPath, bodyPath := c.paths(u)
+	b, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil, false
+	}
+	var e cachedResponse
+	if json.Unmarshal(b, &e) != nil || e.URL != u {
+		return nil, nil, false
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil || sha256Hex(body) != e.BodySHA256 {
+		return nil, nil, false
+	}
+	return &e, body, true
+}
+
+// Écrit un fichier par renommage, pour ne jamais laisser de copie à moitié écrite
+// (le fichier temporaire est unique : plusieurs workers peuvent écrire en même temps)
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// Garde une réponse (le corps seulement s'il est fourni, sinon seules les métadonnées changent).
+// Le corps est écrit avant les métadonnées qui le désignent par son empreinte
+func (c *httpCache) store(e *cachedResponse, body []byte) error {
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return err
+	}
+	metaPath, bodyPath := c.paths(e.URL)
+	if body != nil {
+		if err := writeFileAtomic(bodyPath, body); err != nil {
+			return err
+		}
+	}
+	b, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(metaPath, b)
+}
+
+// Réponse reconstruite à partir du cache
+func (e *cachedResponse) response(body []byte) *httpResponse {
+	h := make(http.Header)
+	for k, v := range map[string]string{"Content-Type": e.ContentType, "ETag": e.ETag, "Last-Modified": e.LastModified} {
+		if v != "" {
+			h.Set(k, v)
+		}
+	}
+	return &httpResponse{StatusCode: http.StatusOK, Status: e.Status, Header: h, Body: body}
+}
+
+// GET à travers le cache
+func (c *httpCache) get(client *httpClient, u string) (*httpResponse, error) {
+	e, body, ok := c.load(u)
+	if c.offline {
+		if !ok {
+			return nil, fmt.Errorf("%w : %s", errOffline, u)
+		}
+		return e.response(body), nil
+	}
+	if ok && time.Since(e.Fetched) < c.ttl {
+		return e.response(body), nil
+	}
+
+	// Copie trop ancienne : requête conditionnelle
+	header := make(http.Header)
+	if ok {
+		if e.ETag != "" {
+			header.Set("If-None-Match", e.ETag)
+		}
+		if e.LastModified != "" {
+			header.Set("If-Modified-Since", e.LastModified)
+		}
+	}
+	resp, err := client.get(u, header)
+	if err == nil && resp.StatusCode >= 500 {
+		err = fmt.Errorf("HTTP %s", resp.Status)
+	}
+	if err != nil && ok {
+		// Serveur injoignable : mieux vaut une copie ancienne que rien
+		fmt.Printf("Serveur indisponible (%v), copie du cache du %s utilisée.\n", err, e.Fetched.Format(time.RFC3339))
+		return e.response(body), nil
+	}
+	if resp == nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		e.Fetched = time.Now()
+		if err := c.store(e, nil); err != nil {
+			fmt.Println("Erreur cache HTTP :", err)
+		}
+		return e.response(body), nil
+	case resp.StatusCode == http.StatusOK:
+		e = &cachedResponse{
+			URL:          u,
+			Status:       resp.Status,
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			ContentType:  resp.Header.Get("Content-Type"),
+			BodySHA256:   sha256Hex(resp.Body),
+			Fetched:      time.Now(),
+		}
+		if err := c.store(e, resp.Body); err != nil {
+			fmt.Println("Erreur cache HTTP :", err)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Serveur qui gère ETag et Last-Modified ; body est la version courante de la page
+type cacheServer struct {
+	*httptest.Server
+	body        atomic.Value // string
+	hits        atomic.Int32
+	notModified atomic.Int32
+	down        atomic.Bool // répond 503
+}
+
+func newCacheServer(t *testing.T) *cacheServer {
+	t.Helper()
+	s := &cacheServer{}
+	s.body.Store("version 1")
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		if s.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body := s.body.Load().(string)
+		etag := `"` + sha256Hex([]byte(body))[:8] + `"`
+		if r.URL.Path == "/sans-etag" {
+			w.Header().Set("Last-Modified", "Fri, 27 Feb 2026 11:33:20 GMT")
+			if r.Header.Get("If-Modified-Since") != "" {
+				s.notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		} else {
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				s.notModified.Add(1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Lit u à travers le cache et vérifie le corps obtenu
+func cacheGet(t *testing.T, c *httpCache, u, want string) {
+	t.Helper()
+	client, _ := testHTTPClient(Config{})
+	resp, err := c.get(client, u)
+	if err != nil {
+		t.Fatalf("%s : %v", u, err)
+	}
+	if resp.StatusCode != http.StatusOK || string(resp.Body) != want {
+		t.Fatalf("%s : %d %q, attendu 200 %q", u, resp.StatusCode, resp.Body, want)
+	}
+}
+
+// Copie expirée : requête conditionnelle, 304 et corps repris du cache ; page changée : nouvelle copie
+func TestHTTPCacheRevalidate(t *testing.T) {
+	for _, path := range []string{"/page", "/sans-etag"} {
+		srv := newCacheServer(t)
+		c := &httpCache{dir: t.TempDir()} // durée de vie nulle : toujours revalider
+		u := srv.URL + path
+
+		cacheGet(t, c, u, "version 1")
+		cacheGet(t, c, u, "version 1")
+		if srv.hits.Load() != 2 || srv.notModified.Load() != 1 {
+			t.Errorf("%s : %d requête(s) dont %d 304, attendu 2 dont 1", path, srv.hits.Load(), srv.notModified.Load())
+		}
+		if path == "/page" {
+			srv.body.Store("version 2")
+			cacheGet(t, c, u, "version 2")
+			cacheGet(t, c, u, "version 2")
+			if srv.notModified.Load() != 2 {
+				t.Errorf("%s : %d réponse(s) 304 après la mise à jour, attendu 2", path, srv.notModified.Load())
+			}
+		}
+	}
+}
+
+func TestHTTPCacheTTL(t *testing.T) {
+	srv := newCacheServer(t)
+	c := &httpCache{dir: t.TempDir(), ttl: time.Hour}
+	u := srv.URL + "/page"
+
+	cacheGet(t, c, u, "version 1")
+	srv.body.Store("version 2")
+	cacheGet(t, c, u, "version 1") // copie récente : pas de requête
+	if srv.hits.Load() != 1 {
+		t.Errorf("%d requête(s) pendant la durée de vie, attendu 1", srv.hits.Load())
+	}
+
+	// Copie vieillie de deux heures : revalidée, la page a changé
+	e, body, ok := c.load(u)
+	if !ok {
+		t.Fatal("copie absente du cache")
+	}
+	e.Fetched = time.Now().Add(-2 * time.Hour)
+	if err := c.store(e, body); err != nil {
+		t.Fatal(err)
+	}
+	cacheGet(t, c, u, "version 2")
+	if srv.hits.Load() != 2 {
+		t.Errorf("%d requête(s) après expiration, attendu 2", srv.hits.Load())
+	}
+}
+
+// Serveur en panne ou arrêté : la copie, même ancienne, est utilisée
+func TestHTTPCacheFallback(t *testing.T) {
+	srv := newCacheServer(t)
+	c := &httpCache{dir: t.TempDir()}
+	u := srv.URL + "/page"
+	cacheGet(t, c, u, "version 1")
+
+	srv.down.Store(true)
+	cacheGet(t, c, u, "version 1")
+	srv.Close()
+	cacheGet(t, c, u, "version 1")
+
+	// Sans copie : l'erreur du serveur est rendue
+	client, _ := testHTTPClient(Config{})
+	if _, err := c.get(client, srv.URL+"/autre"); err == nil {
+		t.Error("serveur arrêté, adresse absente du cache : erreur attendue")
+	}
+}
+
+func TestHTTPCacheOffline(t *testing.T) {
+	srv := newCacheServer(t)
+	dir := t.TempDir()
+	u := srv.URL + "/page"
+	cacheGet(t, &httpCache{dir: dir}, u, "version 1")
+
+	c := &httpCache{dir: dir, offline: true}
+	cacheGet(t, c, u, "version 1")
+	client, _ := testHTTPClient(Config{})
+	if _, err := c.get(client, srv.URL+"/autre"); !errors.Is(err, errOffline) {
+		t.Errorf("adresse absente hors ligne : %v, attendu errOffline", err)
+	}
+	if srv.hits.Load() != 1 {
+		t.Errorf("%d requête(s) hors ligne, aucune attendue", srv.hits.Load()-1)
+	}
+}
+
+// Corps et métadonnées dépareillés ou abîmés : la copie est ignorée ; aucun fichier temporaire ne reste
+func TestHTTPCacheFiles(t *testing.T) {
+	srv := newCacheServer(t)
+	c := &httpCache{dir: t.TempDir(), ttl: time.Hour}
+	u := srv.URL + "/page"
+	cacheGet(t, c, u, "version 1")
+
+	tmp, _ := filepath.Glob(filepath.Join(c.dir, "*.tmp"))
+	files, _ := os.ReadDir(c.dir)
+	if len(tmp) != 0 || len(files) != 2 {
+		t.Errorf("%d fichier(s) dans le cache, dont %d temporaire(s)", len(files), len(tmp))
+	}
+
+	meta, body := c.paths(u)
+	os.WriteFile(body, []byte("corps d'une autre réponse"), 0644)
+	if _, _, ok := c.load(u); ok {
+		t.Error("corps dépareillé utilisé")
+	}
+	os.WriteFile(meta, []byte("{"), 0644)
+	if _, _, ok := c.load(u); ok {
+		t.Error("métadonnées illisibles utilisées")
+	}
+	cacheGet(t, c, u, "version 1") // téléchargée de nouveau
+	if srv.hits.Load() != 2 {
+		t.Errorf("%d requête(s), attendu 2", srv.hits.Load())
+	}
+
+	// Réponse en erreur : rien n'est gardé
+	client, _ := testHTTPClient(Config{})
+	srv.down.Store(true)
+	c.get(client, srv.URL+"/erreur")
+	if _, _, ok := c.load(srv.URL + "/erreur"); ok {
+		t.Error("réponse 503 gardée")
+	}
+}
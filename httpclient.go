@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ------- Client HTTP --------
+// Toutes les requêtes passent par ce client : délais maximums (connexion et requête complète),
+// nouvelles tentatives avec attente exponentielle et aléa pour les erreurs réseau, les 429 et les 5xx
+// (en respectant l'en-tête Retry-After), nombre de redirections limité, débit par hôte limité
+// (http_rate) et proxy lu dans HTTP_PROXY / HTTPS_PROXY / NO_PROXY. Le client ne dépend que
+// de la config : il peut être créé avec newHTTPClient pour viser un serveur de test (httptest).
+
+// Réponse lue en entier
+type httpResponse struct {
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       []byte
+}
+
+// Client avec nouvelles tentatives
+type httpClient struct {
+	client    *http.Client
+	userAgent string
+	retries   int           // nouvelles tentatives après le premier essai
+	retryBase time.Duration // attente avant la première nouvelle tentative, doublée ensuite
+	retryMax  time.Duration // attente maximale entre deux essais
+	limiter   *hostLimiter  // débit par hôte, pour chaque requête envoyée
+	sleep     func(time.Duration)
+}
+
+// Limite de débit par hôte : chaque appel à wait réserve le prochain créneau libre de l'hôte
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     map[string]time.Time
+}
+
+func newHostLimiter(rate float64) *hostLimiter {
+	l := &hostLimiter{next: make(map[string]time.Time)}
+	if rate > 0 {
+		l.interval = time.Duration(float64(time.Second) / rate)
+	}
+	return l
+}
+
+// Attend le créneau de l'hôte
+func (l *hostLimiter) wait(host string) {
+	if l.interval <= 0 {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	t := l.next[host]
+	if t.Before(now) {
+		t = now
+	}
+	l.next[host] = t.Add(l.interval)
+	l.mu.Unlock()
+	time.Sleep(time.Until(t))
+}
+
+// Crée un client à partir des clés http_* de la config
+func newHTTPClient(cfg Config) *httpClient {
+	connect := time.Duration(cfg.HTTPConnectTimeoutMs) * time.Millisecond
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: connect, KeepAlive: 30 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   connect,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConnsPerHost:   max(cfg.WikiCrawlConcurrency, 2),
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	maxRedirects := cfg.HTTPMaxRedirects
+	limiter := newHostLimiter(cfg.HTTPRate)
+	return &httpClient{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   time.Duration(cfg.HTTPTimeoutMs) * time.Millisecond,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > maxRedirects {
+					return fmt.Errorf("plus de %d redirection(s)", maxRedirects)
+				}
+				limiter.wait(req.URL.Host)
+				return nil
+			},
+		},
+		userAgent: cfg.UserAgent,
+		retries:   cfg.HTTPRetries,
+		retryBase: time.Duration(cfg.HTTPRetryBaseMs) * time.Millisecond,
+		retryMax:  time.Duration(cfg.HTTPRetryMaxMs) * time.Millisecond,
+		limiter:   limiter,
+		sleep:     time.Sleep,
+	}
+}
+
+// Client partagé par le programme (créé au premier appel, la config ne change pas ensuite)
+var (
+	sharedHTTP     *httpClient
+	sharedHTTPOnce sync.Once
+)
+
+func defaultHTTPClient(cfg Config) *httpClient {
+	sharedHTTPOnce.Do(func() { sharedHTTP = newHTTPClient(cfg) })
+	return sharedHTTP
+}
+
+// Indique si une erreur réseau mérite un nouvel essai (délai dépassé, connexion refusée ou coupée) ;
+// un nom d'hôte inconnu ne le mérite pas
+func retryableError(err error) bool {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// Indique si un code HTTP mérite un nouvel essai : trop de requêtes ou erreur passagère du serveur
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code != http.StatusNotImplemented)
+}
+
+// Attente demandée par l'en-tête Retry-After (secondes ou date HTTP), 0 si absent
+func retryAfter(h http.Header, now time.Time) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil && s >= 0 {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// Attente avant la nouvelle tentative n (0 pour la première) : base * 2^n, plafonnée,
+// tirée au hasard entre la moitié et la totalité pour que les clients ne réessaient pas ensemble
+func (c *httpClient) backoff(n int) time.Duration {
+	d := c.retryMax
+	if n < 30 && c.retryBase<<n < c.retryMax {
+		d = c.retryBase << n
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// GET avec le User-Agent de la config et les en-têtes donnés, en réessayant si besoin.
+// Chaque essai attend le créneau de l'hôte (hors du délai maximum de la requête).
+// La réponse est rendue quel que soit son code : c'est à l'appelant de le vérifier.
+func (c *httpClient) get(u string, header http.Header) (*httpResponse, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("User-Agent", c.userAgent)
+
+		var res *httpResponse
+		c.limiter.wait(req.URL.Host)
+		resp, err := c.client.Do(req)
+		if err == nil {
+			res = &httpResponse{StatusCode: resp.StatusCode, Status: resp.Status, Header: resp.Header}
+			res.Body, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+
+		// Faut-il réessayer, et après combien de temps ?
+		var wait time.Duration
+		var reason string
+		switch {
+		case err != nil:
+			if !retryableError(err) {
+				return nil, err
+			}
+			reason = err.Error()
+		case retryableStatus(res.StatusCode):
+			reason = "HTTP " + res.Status
+			wait = retryAfter(res.Header, time.Now())
+		default:
+			return res, nil
+		}
+		if attempt >= c.retries || wait > c.retryMax {
+			if err != nil {
+				return nil, err
+			}
+			return res, nil
+		}
+		wait = max(wait, c.backoff(attempt))
+		fmt.Printf("%s : %s, nouvel essai dans %v (%d/%d)\n", u, reason, wait.Round(time.Millisecond), attempt+1, c.retries)
+		c.sleep(wait)
+	}
+}
+
+// Page absente (HTTP 404) : c'est à l'appelant de dire ce qui manque (article, API, page web)
+var errNotFound = errors.New("page introuvable (HTTP 404)")
+
+// GET par le client HTTP du programme, à travers le cache si http_cache est actif ou hors ligne ;
+// erreur si le code HTTP n'est pas 200 (errNotFound pour un 404)
+func httpGet(cfg Config, u string) ([]byte, http.Header, error) {
+	var resp *httpResponse
+	var err error
+	if cfg.HTTPCache || cfg.Offline {
+		resp, err = newHTTPCache(cfg).get(defaultHTTPClient(cfg), u)
+	} else {
+		resp, err = defaultHTTPClient(cfg).get(u, nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, resp.Header, errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.Header, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return resp.Body, resp.Header, nil
+}
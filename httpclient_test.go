@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Client de test : les attentes entre deux essais sont notées au lieu d'être faites
+func testHTTPClient(cfg Config) (*httpClient, *[]time.Duration) {
+	c := newHTTPClient(cfg)
+	waits := new([]time.Duration)
+	c.sleep = func(d time.Duration) { *waits = append(*waits, d) }
+	return c, waits
+}
+
+// Serveur qui répond failures fois avec le code donné, puis 200
+func flakyServer(t *testing.T, code, failures int, header http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if int(hits.Add(1)) <= failures {
+			for k, v := range header {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(code)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestHTTPClientRetry(t *testing.T) {
+	cfg := Config{HTTPRetries: 3, HTTPRetryBaseMs: 100, HTTPRetryMaxMs: 1000}
+	tests := []struct {
+		name      string
+		code      int
+		failures  int
+		wantCode  int
+		wantHits  int
+		wantWaits int
+	}{
+		{"429 puis succès", http.StatusTooManyRequests, 2, 200, 3, 2},
+		{"503 puis succès", http.StatusServiceUnavailable, 1, 200, 2, 1},
+		{"500 puis succès", http.StatusInternalServerError, 3, 200, 4, 3},
+		{"502 à chaque essai", http.StatusBadGateway, 10, 502, 4, 3},
+		{"501 non réessayé", http.StatusNotImplemented, 10, 501, 1, 0},
+		{"404 non réessayé", http.StatusNotFound, 10, 404, 1, 0},
+		{"400 non réessayé", http.StatusBadRequest, 10, 400, 1, 0},
+		{"403 non réessayé", http.StatusForbidden, 10, 403, 1, 0},
+	}
+	for _, tt := range tests {
+		srv, hits := flakyServer(t, tt.code, tt.failures, nil)
+		c, waits := testHTTPClient(cfg)
+		resp, err := c.get(srv.URL, nil)
+		if err != nil {
+			t.Errorf("%s : %v", tt.name, err)
+			continue
+		}
+		if resp.StatusCode != tt.wantCode {
+			t.Errorf("%s : code %d, attendu %d", tt.name, resp.StatusCode, tt.wantCode)
+		}
+		if int(hits.Load()) != tt.wantHits {
+			t.Errorf("%s : %d requête(s), attendu %d", tt.name, hits.Load(), tt.wantHits)
+		}
+		if len(*waits) != tt.wantWaits {
+			t.Errorf("%s : %d attente(s), attendu %d", tt.name, len(*waits), tt.wantWaits)
+		}
+		// Attente exponentielle : entre la moitié et la totalité de base * 2^n, plafonnée
+		for n, d := range *waits {
+			full := min(100*time.Millisecond<<n, time.Second)
+			if d < full/2 || d > full {
+				t.Errorf("%s : attente %d = %v, attendu entre %v et %v", tt.name, n, d, full/2, full)
+			}
+		}
+	}
+}
+
+func TestHTTPClientRetryAfter(t *testing.T) {
+	cfg := Config{HTTPRetries: 2, HTTPRetryBaseMs: 10, HTTPRetryMaxMs: 5000}
+	date := time.Now().Add(4 * time.Second).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		name     string
+		value    string
+		wantCode int
+		wantHits int
+		min, max time.Duration // bornes de la première attente (0 = pas d'attente)
+	}{
+		{"secondes", "2", 200, 2, 2 * time.Second, 2 * time.Second},
+		{"date HTTP", date, 200, 2, 2 * time.Second, 4 * time.Second},
+		{"invalide : attente exponentielle", "bientôt", 200, 2, 5 * time.Millisecond, 10 * time.Millisecond},
+		{"trop long : abandon", "60", 429, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		srv, hits := flakyServer(t, http.StatusTooManyRequests, 1, http.Header{"Retry-After": {tt.value}})
+		c, waits := testHTTPClient(cfg)
+		resp, err := c.get(srv.URL, nil)
+		if err != nil {
+			t.Errorf("%s : %v", tt.name, err)
+			continue
+		}
+		if resp.StatusCode != tt.wantCode || int(hits.Load()) != tt.wantHits {
+			t.Errorf("%s : code %d après %d requête(s), attendu %d après %d", tt.name, resp.StatusCode, hits.Load(), tt.wantCode, tt.wantHits)
+		}
+		if tt.max == 0 {
+			if len(*waits) != 0 {
+				t.Errorf("%s : attentes %v, aucune attendue", tt.name, *waits)
+			}
+			continue
+		}
+		if len(*waits) != 1 || (*waits)[0] < tt.min || (*waits)[0] > tt.max {
+			t.Errorf("%s : attentes %v, attendu une entre %v et %v", tt.name, *waits, tt.min, tt.max)
+		}
+	}
+}
+
+func TestHTTPClientRedirects(t *testing.T) {
+	// /r/N redirige vers /r/N-1, /r/0 répond 200
+	var hits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/r/{n}", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		switch n := r.PathValue("n"); n {
+		case "0":
+			w.Write([]byte("ok"))
+		default:
+			prev := []byte(n)
+			prev[0]--
+			http.Redirect(w, r, "/r/"+string(prev), http.StatusFound)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		redirects string
+		wantErr   bool
+		wantHits  int
+	}{
+		{"0", false, 1},
+		{"3", false, 4},
+		{"4", true, 4}, // la 4e redirection n'est pas suivie
+	}
+	for _, tt := range tests {
+		hits.Store(0)
+		c, _ := testHTTPClient(Config{HTTPMaxRedirects: 3, HTTPRetries: 2})
+		resp, err := c.get(srv.URL+"/r/"+tt.redirects, nil)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("%s redirection(s) : erreur %v, attendu erreur = %v", tt.redirects, err, tt.wantErr)
+		}
+		if err == nil && (resp.StatusCode != 200 || string(resp.Body) != "ok") {
+			t.Errorf("%s redirection(s) : %d %q, attendu 200 \"ok\"", tt.redirects, resp.StatusCode, resp.Body)
+		}
+		if int(hits.Load()) != tt.wantHits {
+			t.Errorf("%s redirection(s) : %d requête(s), attendu %d", tt.redirects, hits.Load(), tt.wantHits)
+		}
+	}
+}
+
+func TestHTTPClientTimeout(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+
+	c, waits := testHTTPClient(Config{HTTPTimeoutMs: 50, HTTPRetries: 1, HTTPRetryBaseMs: 10, HTTPRetryMaxMs: 10})
+	started := time.Now()
+	_, err := c.get(srv.URL, nil)
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("erreur %v, délai dépassé attendu", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("abandon après %v, attendu environ 2 x 50ms", elapsed)
+	}
+	// Un délai dépassé mérite un nouvel essai
+	if hits.Load() != 2 || len(*waits) != 1 {
+		t.Errorf("%d requête(s) et %d attente(s), attendu 2 et 1", hits.Load(), len(*waits))
+	}
+}
+
+func TestHostLimiter(t *testing.T) {
+	l := newHostLimiter(20) // une requête toutes les 50ms par hôte
+	started := time.Now()
+	for range 3 {
+		l.wait("a.example")
+	}
+	l.wait("b.example") // autre hôte : pas d'attente
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Errorf("3 requêtes sur un hôte en %v, attendu au moins 100ms", elapsed)
+	}
+
+	l = newHostLimiter(0)
+	started = time.Now()
+	for range 10 {
+		l.wait("a.example")
+	}
+	if elapsed := time.Since(started); elapsed > 50*time.Millisecond {
+		t.Errorf("sans limite : %v d'attente", elapsed)
+	}
+}
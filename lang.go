@@ -0,0 +1,187 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ------- Détection de langue --------
+// Détection hors-ligne par profils de n-grammes de caractères.
+// Les textes d'apprentissage sont embarqués dans le binaire (dossier langdata/),
+// un profil (log-probabilités des 1 à 3-grammes) est calculé par langue au premier appel.
+
+//go:embed langdata/*.txt
+var langData embed.FS
+
+const (
+	langMaxGram    = 3    // taille maximale des n-grammes
+	langMaxSample  = 3000 // nombre maximal de n-grammes lus dans le texte analysé
+	langMinLetters = 20   // en dessous, le texte est trop court pour conclure
+	langEvidence   = 40   // nombre de n-grammes au-delà duquel les scores sont ramenés à cette échelle
+	langUnknown    = "inconnue"
+)
+
+// Profil d'une langue : log-probabilité de chaque n-gramme et valeur pour un n-gramme jamais vu
+type langProfile struct {
+	code    string
+	logProb map[string]float64
+	unknown float64
+}
+
+// Résultat de la détection : code de langue (fr, en, ...) et confiance entre 0 et 1
+type langResult struct {
+	Lang       string
+	Confidence float64
+}
+
+// Profils chargés au premier appel de detectLanguage (une seule fois, même appelé par plusieurs goroutines)
+var (
+	langProfiles []langProfile
+	langOnce     sync.Once
+)
+
+// Texte affiché pour un résultat, par exemple "fr (98.5%)"
+func (r langResult) String() string {
+	if r.Lang == langUnknown {
+		return langUnknown
+	}
+	return fmt.Sprintf("%s (%.1f%%)", r.Lang, r.Confidence*100)
+}
+
+// Découpe un texte en n-grammes (1 à 3 caractères) sur les mots en minuscules,
+// chaque mot étant entouré d'espaces pour capturer les débuts et fins de mots
+func textNgrams(text string, limit int) ([]string, int) {
+	var grams []string
+	letters := 0
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, w := range words {
+		w = strings.Trim(w, "'")
+		if w == "" {
+			continue
+		}
+		runes := []rune(" " + w + " ")
+		letters += len(runes) - 2
+		for n := 1; n <= langMaxGram; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				g := string(runes[i : i+n])
+				if g == " " {
+					continue
+				}
+				grams = append(grams, g)
+			}
+		}
+		if limit > 0 && len(grams) >= limit {
+			break
+		}
+	}
+	return grams, letters
+}
+
+// Construit les profils à partir des textes embarqués (lissage de Laplace)
+func loadLangProfiles() []langProfile {
+	files, err := langData.ReadDir("langdata")
+	if err != nil {
+		return nil
+	}
+
+	counts := make(map[string]map[string]int)
+	vocab := make(map[string]bool)
+	for _, f := range files {
+		data, err := langData.ReadFile(path.Join("langdata", f.Name()))
+		if err != nil {
+			continue
+		}
+		code := strings.TrimSuffix(f.Name(), path.Ext(f.Name()))
+		grams, _ := textNgrams(string(data), 0)
+		c := make(map[string]int)
+		for _, g := range grams {
+			c[g]++
+			vocab[g] = true
+		}
+		counts[code] = c
+	}
+
+	var profiles []langProfile
+	for code, c := range counts {
+		total := 0
+		for _, n := range c {
+			total += n
+		}
+		denom := float64(total + len(vocab))
+		p := langProfile{code: code, logProb: make(map[string]float64, len(c)), unknown: math.Log(1 / denom)}
+		for g, n := range c {
+			p.logProb[g] = math.Log(float64(n+1) / denom)
+		}
+		profiles = append(profiles, p)
+	}
+
+	// Ordre stable pour départager les égalités
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].code < profiles[j].code })
+	return profiles
+}
+
+// Détecte la langue d'un texte : classifieur bayésien naïf sur les n-grammes,
+// la confiance est la probabilité a posteriori de la langue retenue
+func detectLanguage(text string) langResult {
+	langOnce.Do(func() { langProfiles = loadLangProfiles() })
+
+	grams, letters := textNgrams(text, langMaxSample)
+	if letters < langMinLetters || len(langProfiles) == 0 {
+		return langResult{Lang: langUnknown}
+	}
+
+	// Log-vraisemblance du texte pour chaque langue
+	scores := make([]float64, len(langProfiles))
+	best := 0
+	for i, p := range langProfiles {
+		for _, g := range grams {
+			if lp, ok := p.logProb[g]; ok {
+				scores[i] += lp
+			} else {
+				scores[i] += p.unknown
+			}
+		}
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+
+	// Le bayésien naïf est très sûr de lui sur les longs textes : on ramène les scores
+	// à l'échelle de langEvidence n-grammes avant la normalisation (softmax)
+	scale := 1.0
+	if len(grams) > langEvidence {
+		scale = float64(langEvidence) / float64(len(grams))
+	}
+	sum := 0.0
+	for _, s := range scores {
+		sum += math.Exp((s - scores[best]) * scale)
+	}
+	return langResult{Lang: langProfiles[best].code, Confidence: 1 / sum}
+}
+
+// Mots outils ignorés dans les fréquences de mots, par langue
+var stopWords = map[string]map[string]bool{
+	"fr": wordSet("les des une est que qui dans par pour pas sur son ses aux avec plus mais elle ils sont été ont cette comme tout leur leurs être fait sans entre aussi dont très peut lors alors donc"),
+	"en": wordSet("the and that this with for are was were from have has had not but his her its they their them which what when will would there been into more also than then only can all one about"),
+	"de": wordSet("der die das und ist ein eine einen dem den des mit von auf für nicht sich auch als wie aus bei oder nach wird sind war zum zur über noch nur"),
+	"es": wordSet("los las del una que por con para como pero sus más fue son está este esta entre sobre también sin hay ser han desde todo"),
+	"it": wordSet("gli che del della per con una sono non come più anche dei delle nel nella alla questo questa suo sua loro era stato essere tra"),
+	"pt": wordSet("que dos das uma para com por não como mais foi são seu sua pelo pela também entre sobre isso esta este muito ser tem"),
+}
+
+// Ensemble de mots à partir d'une liste séparée par des espaces
+func wordSet(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{"français", "Le chat dort sur le canapé pendant que les enfants jouent dans le jardin avec leurs amis.", "fr"},
+		{"anglais", "The quick brown fox jumps over the lazy dog while the children are playing in the garden.", "en"},
+		{"allemand", "Der schnelle braune Fuchs springt über den faulen Hund, während die Kinder im Garten spielen.", "de"},
+		{"espagnol", "El rápido zorro marrón salta sobre el perro perezoso mientras los niños juegan en el jardín.", "es"},
+		{"français sans accents", "Il etait une fois une petite fille qui vivait dans une maison au bord de la foret.", "fr"},
+		{"texte court", "Bonjour", langUnknown},
+		{"vide", "", langUnknown},
+		{"nombres et ponctuation", "12 345, 678.90 ; 2026-10-19 -- 42 !!! 1234567890 987654321", langUnknown},
+	}
+	for _, tt := range tests {
+		got := detectLanguage(tt.text)
+		if got.Lang != tt.want {
+			t.Errorf("%s : langue %s, attendu %s", tt.name, got, tt.want)
+		}
+		if got.Lang != langUnknown && (got.Confidence <= 0.5 || got.Confidence > 1) {
+			t.Errorf("%s : confiance %v, attendu entre 0.5 et 1", tt.name, got.Confidence)
+		}
+	}
+}
+
+func TestLangResultString(t *testing.T) {
+	tests := []struct {
+		r    langResult
+		want string
+	}{
+		{langResult{Lang: "fr", Confidence: 0.985}, "fr (98.5%)"},
+		{langResult{Lang: langUnknown}, langUnknown},
+	}
+	for _, tt := range tests {
+		if got := tt.r.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, attendu %q", tt.r, got, tt.want)
+		}
+	}
+}
+
+// Les profils sont chargés une seule fois, même par plusieurs goroutines à la fois (go test -race)
+func TestDetectLanguageConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := detectLanguage("Les profils de langue sont partagés entre les workers de l'analyse."); got.Lang != "fr" {
+				t.Errorf("langue %s, attendu fr", got)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ------- Analyse de logs --------
+// Reconnaît les formats courants (horodatage RFC3339 / ISO, syslog, Apache, log Go, lignes JSON),
+// les niveaux (level=error, [ERROR], ERROR ...), et permet de filtrer par période et par niveau.
+
+// Une ligne de log analysée
+type logRecord struct {
+	Line    string
+	Time    time.Time
+	HasTime bool
+	Level   string // niveau normalisé (ERROR, WARN, INFO...) ou "" si aucun
+}
+
+// Formats d'horodatage reconnus : expression régulière + layouts Go correspondants
+var logTimeFormats = []struct {
+	re      *regexp.Regexp
+	layouts []string
+}{
+	// 2026-02-27T11:33:20Z, 2026-02-27 11:33:20.123+01:00 ...
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`),
+		[]string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999Z0700", "2006-01-02 15:04:05.999999999Z07:00",
+			"2006-01-02 15:04:05.999999999Z0700", "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999"}},
+	// 27/Feb/2026:11:33:20 +0100 (Apache / Nginx)
+	{regexp.MustCompile(`\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}`),
+		[]string{"02/Jan/2006:15:04:05 -0700"}},
+	// 2026/02/27 11:33:20 (package log de Go)
+	{regexp.MustCompile(`\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)?`),
+		[]string{"2006/01/02 15:04:05.999999999"}},
+	// Feb 27 11:33:20 (syslog, sans année)
+	{regexp.MustCompile(`^[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}`),
+		[]string{time.Stamp}},
+}
+
+// Niveaux reconnus et leur forme normalisée
+var logLevels = map[string]string{
+	"TRACE": "TRACE", "DEBUG": "DEBUG", "INFO": "INFO", "NOTICE": "NOTICE",
+	"WARN": "WARN", "WARNING": "WARN", "ERROR": "ERROR", "ERR": "ERROR",
+	"CRIT": "CRITICAL", "CRITICAL": "CRITICAL", "FATAL": "FATAL", "PANIC": "PANIC",
+	"ALERT": "ALERT", "EMERG": "EMERG",
+}
+
+var (
+	logLevelKV      = regexp.MustCompile(`(?i)\b(?:level|lvl|severity)=["']?(\w+)`)
+	logLevelBracket = regexp.MustCompile(`[\[<(]([A-Za-z]+)[\]>)]`)
+	logLevelWord    = regexp.MustCompile(`\b([A-Z]{3,8})\b`)
+)
+
+// Clés JSON reconnues pour l'horodatage et le niveau
+var (
+	logJSONTimeKeys  = []string{"time", "ts", "timestamp", "@timestamp", "date", "datetime"}
+	logJSONLevelKeys = []string{"level", "lvl", "severity", "loglevel", "log.level"}
+)
+
+// Normalise un niveau, "" si le mot n'est pas un niveau connu
+func normalizeLevel(s string) string {
+	return logLevels[strings.ToUpper(s)]
+}
+
+// Cherche un horodatage dans un texte. Un horodatage sans fuseau est en heure locale,
+// comme les dates saisies pour le filtrage (parseUserTime)
+func parseLogTime(s string) (time.Time, bool) {
+	for _, f := range logTimeFormats {
+		m := f.re.FindString(s)
+		if m == "" {
+			continue
+		}
+		m = strings.Replace(m, ",", ".", 1)
+		for _, layout := range f.layouts {
+			if t, err := time.ParseInLocation(layout, m, time.Local); err == nil {
+				// syslog ne donne pas l'année : on prend l'année courante
+				if layout == time.Stamp {
+					t = time.Date(time.Now().Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.Local)
+				}
+				return t, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// Analyse une ligne au format JSON ({"time": ..., "level": ..., "msg": ...})
+func parseJSONLogLine(line string) (logRecord, bool) {
+	var obj map[string]any
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		return logRecord{}, false
+	}
+	rec := logRecord{Line: line}
+	for _, k := range logJSONTimeKeys {
+		switch v := obj[k].(type) {
+		case string:
+			rec.Time, rec.HasTime = parseLogTime(v)
+		case float64:
+			// Epoch en secondes ou en millisecondes
+			if v > 1e12 {
+				rec.Time = time.UnixMilli(int64(v))
+			} else {
+				rec.Time = time.Unix(int64(v), 0)
+			}
+			rec.HasTime = true
+		}
+		if rec.HasTime {
+			break
+		}
+	}
+	for _, k := range logJSONLevelKeys {
+		if v, ok := obj[k].(string); ok {
+			rec.Level = normalizeLevel(v)
+			break
+		}
+	}
+	return rec, true
+}
+
+// Analyse une ligne de log texte ou JSON
+func parseLogLine(line string) logRecord {
+	if strings.HasPrefix(line, "{") {
+		if rec, ok := parseJSONLogLine(line); ok {
+			return rec
+		}
+	}
+
+	rec := logRecord{Line: line}
+	rec.Time, rec.HasTime = parseLogTime(line)
+
+	// Niveau : level=xxx, puis [XXX], puis un mot en majuscules
+	if m := logLevelKV.FindStringSubmatch(line); m != nil {
+		rec.Level = normalizeLevel(m[1])
+	}
+	if rec.Level == "" {
+		for _, m := range logLevelBracket.FindAllStringSubmatch(line, -1) {
+			if rec.Level = normalizeLevel(m[1]); rec.Level != "" {
+				break
+			}
+		}
+	}
+	if rec.Level == "" {
+		for _, m := range logLevelWord.FindAllStringSubmatch(line, -1) {
+			if rec.Level = normalizeLevel(m[1]); rec.Level != "" {
+				break
+			}
+		}
+	}
+	return rec
+}
+
+// Analyse toutes les lignes : une ligne sans horodatage (trace de pile, suite de message)
+// hérite de l'horodatage et du niveau de la ligne précédente
+func parseLogLines(lines []string) []logRecord {
+	records := make([]logRecord, 0, len(lines))
+	var prev logRecord
+	for _, l := range lines {
+		rec := parseLogLine(l)
+		if !rec.HasTime && prev.HasTime {
+			rec.Time, rec.HasTime = prev.Time, true
+			if rec.Level == "" {
+				rec.Level = prev.Level
+			}
+		}
+		records = append(records, rec)
+		prev = rec
+	}
+	return records
+}
+
+// Lit une date saisie par l'utilisateur (vide = pas de borne), en heure locale sauf fuseau explicite
+func parseUserTime(s string) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false, nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true, nil
+		}
+	}
+	return time.Time{}, false, fmt.Errorf("date invalide : %s (format attendu : 2006-01-02 15:04)", s)
+}
+
+// Filtre par période (bornes comprises) et par niveau
+type logFilter struct {
+	From, To       time.Time
+	HasFrom, HasTo bool
+	Levels         map[string]bool // niveaux gardés, vide = tous
+}
+
+// Indique si une ligne passe le filtre ; avec une période, les lignes sans horodatage sont écartées
+func (f logFilter) keep(r logRecord) bool {
+	if (f.HasFrom || f.HasTo) && !r.HasTime {
+		return false
+	}
+	if f.HasFrom && r.Time.Before(f.From) {
+		return false
+	}
+	if f.HasTo && r.Time.After(f.To) {
+		return false
+	}
+	return len(f.Levels) == 0 || f.Levels[r.Level]
+}
+
+// Mode log du choix A : stats par niveau, histogramme, filtrage par période et niveau
+func analyzeLogs(cfg Config, reader *bufio.Reader, lines []string) {
+	records := parseLogLines(lines)
+
+	// Comptage par niveau et bornes temporelles
+	levels := make(map[string]int)
+	var first, last time.Time
+	timed := 0
+	for _, r := range records {
+		lvl := r.Level
+		if lvl == "" {
+			lvl = "(aucun)"
+		}
+		levels[lvl]++
+		if r.HasTime {
+			if timed == 0 || r.Time.Before(first) {
+				first = r.Time
+			}
+			if timed == 0 || r.Time.After(last) {
+				last = r.Time
+			}
+			timed++
+		}
+	}
+
+	fmt.Println("Lignes horodatées :", timed, "/", len(records))
+	if timed > 0 {
+		fmt.Println("Premier horodatage :", first.Format(time.RFC3339))
+		fmt.Println("Dernier horodatage :", last.Format(time.RFC3339))
+	}
+
+	// Niveaux triés par nombre décroissant
+	fmt.Println("Lignes par niveau :")
+	names := make([]string, 0, len(levels))
+	for k := range levels {
+		names = append(names, k)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if levels[names[i]] != levels[names[j]] {
+			return levels[names[i]] > levels[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	for _, k := range names {
+		fmt.Printf("  %-10s %d\n", k, levels[k])
+	}
+
+	// Histogramme par minute si la période couvre moins de 2 heures, par heure sinon
+	if timed > 0 {
+		bucket, layout := time.Minute, "2006-01-02 15:04"
+		if last.Sub(first) > 2*time.Hour {
+			bucket, layout = time.Hour, "2006-01-02 15h"
+		}
+		printLogHistogram(records, bucket, layout)
+	}
+
+	// Filtrage par période et par niveau
+	fmt.Print("Depuis (ex: 2026-02-27 11:00, ENTER = début) : ")
+	fromStr, _ := reader.ReadString('\n')
+	fmt.Print("Jusqu'à (ENTER = fin) : ")
+	toStr, _ := reader.ReadString('\n')
+	fmt.Print("Niveaux à garder (ex: ERROR,WARN, ENTER = tous) : ")
+	lvlStr, _ := reader.ReadString('\n')
+
+	from, hasFrom, err := parseUserTime(fromStr)
+	if err != nil {
+		fmt.Println("Erreur :", err)
+		return
+	}
+	to, hasTo, err := parseUserTime(toStr)
+	if err != nil {
+		fmt.Println("Erreur :", err)
+		return
+	}
+	filter := logFilter{From: from, HasFrom: hasFrom, To: to, HasTo: hasTo, Levels: make(map[string]bool)}
+	for _, l := range strings.Split(lvlStr, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			if n := normalizeLevel(l); n != "" {
+				filter.Levels[n] = true
+			} else {
+				fmt.Println("Niveau inconnu ignoré :", l)
+			}
+		}
+	}
+
+	var out strings.Builder
+	count := 0
+	for _, r := range records {
+		if filter.keep(r) {
+			out.WriteString(r.Line + "\n")
+			count++
+		}
+	}
+
+	outFile := filepath.Join(cfg.OutDir, "log_filtered.txt")
+	if err := writeTextFile(outFile, out.String(), cfg.OutputEncoding); err != nil {
+		fmt.Println("Erreur création fichier :", err)
+		return
+	}
+	fmt.Println("Lignes de log retenues :", count, "->", outFile)
+}
+
+// Début de la tranche (minute ou heure) d'un horodatage, dans le fuseau de l'horodatage : Truncate
+// compte à partir de l'instant zéro en UTC et décalerait les tranches d'un fuseau à la demi-heure
+func logBucket(t time.Time, bucket time.Duration) time.Time {
+	minute := t.Minute()
+	if bucket >= time.Hour {
+		minute = 0
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), minute, 0, 0, t.Location())
+}
+
+// Affiche le nombre de lignes par tranche de temps sous forme de barres
+func printLogHistogram(records []logRecord, bucket time.Duration, layout string) {
+	counts := make(map[time.Time]int)
+	max := 0
+	for _, r := range records {
+		if !r.HasTime {
+			continue
+		}
+		k := logBucket(r.Time, bucket)
+		counts[k]++
+		if counts[k] > max {
+			max = counts[k]
+		}
+	}
+
+	keys := make([]time.Time, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Before(keys[j]) })
+
+	fmt.Println("Histogramme :")
+	for _, k := range keys {
+		width := counts[k] * 40 / max
+		if width == 0 {
+			width = 1
+		}
+		fmt.Printf("  %s | %-40s %s\n", k.Format(layout), strings.Repeat("#", width), strconv.Itoa(counts[k]))
+	}
+}
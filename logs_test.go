@@ -0,0 +1,186 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// Remplace le fuseau local le temps d'un test (les horodatages sans fuseau sont en heure locale)
+func withLocal(t *testing.T, loc *time.Location) {
+	t.Helper()
+	saved := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = saved })
+}
+
+func TestParseLogTime(t *testing.T) {
+	paris := time.FixedZone("CET", 3600)
+	withLocal(t, paris)
+	local := func(h, m, s, ns int) time.Time { return time.Date(2026, 2, 27, h, m, s, ns, paris) }
+	tests := []struct {
+		line string
+		want time.Time
+	}{
+		// Avec fuseau : l'instant donné
+		{"2026-02-27T11:33:20Z level=info msg=ok", time.Date(2026, 2, 27, 11, 33, 20, 0, time.UTC)},
+		{"2026-02-27T11:33:20.5+02:00 start", time.Date(2026, 2, 27, 9, 33, 20, 5e8, time.UTC)},
+		{"2026-02-27T11:33:20+0100 start", local(11, 33, 20, 0)},
+		{"2026-02-27 11:33:20.123+01:00 start", local(11, 33, 20, 123e6)},
+		{"2026-02-27 11:33:20-0500 start", time.Date(2026, 2, 27, 16, 33, 20, 0, time.UTC)},
+		{`127.0.0.1 - - [27/Feb/2026:11:33:20 +0000] "GET / HTTP/1.1" 200`, time.Date(2026, 2, 27, 11, 33, 20, 0, time.UTC)},
+		// Sans fuseau : heure locale
+		{"2026-02-27T11:33:20.123 INFO start", local(11, 33, 20, 123e6)},
+		{"2026-02-27 11:33:20 [ERROR] boom", local(11, 33, 20, 0)},
+		{"2026-02-27 11:33:20,456 WARN lent", local(11, 33, 20, 456e6)},
+		{"2026/02/27 11:33:20 serveur démarré", local(11, 33, 20, 0)},
+		{"2026/02/27 11:33:20.000123 serveur démarré", local(11, 33, 20, 123000)},
+	}
+	for _, tt := range tests {
+		got, ok := parseLogTime(tt.line)
+		if !ok || !got.Equal(tt.want) {
+			t.Errorf("parseLogTime(%q) = %v, %v ; attendu %v", tt.line, got, ok, tt.want)
+		}
+	}
+
+	// syslog : sans année, l'année courante, en heure locale
+	got, ok := parseLogTime("Feb 27 11:33:20 hote app[12]: connexion")
+	if want := time.Date(time.Now().Year(), 2, 27, 11, 33, 20, 0, paris); !ok || !got.Equal(want) {
+		t.Errorf("syslog : %v, %v ; attendu %v", got, ok, want)
+	}
+	if got, ok := parseLogTime("pas d'horodatage ici 12:30"); ok {
+		t.Errorf("horodatage trouvé à tort : %v", got)
+	}
+}
+
+func TestParseLogLine(t *testing.T) {
+	withLocal(t, time.UTC)
+	tests := []struct {
+		line, level string
+		hasTime     bool
+	}{
+		{"2026-02-27 11:33:20 level=warning disque plein", "WARN", true},
+		{"2026-02-27 11:33:20 [error] boom", "ERROR", true},
+		{"Feb 27 11:33:20 hote kernel: CRIT temperature", "CRITICAL", true},
+		{`{"ts": 1772192000, "level": "debug", "msg": "ok"}`, "DEBUG", true},
+		{`{"time": "2026-02-27T11:33:20Z", "severity": "FATAL"}`, "FATAL", true},
+		{"    at main.go:12", "", false},
+		{"OK tout va bien", "", false},
+	}
+	for _, tt := range tests {
+		r := parseLogLine(tt.line)
+		if r.Level != tt.level || r.HasTime != tt.hasTime {
+			t.Errorf("parseLogLine(%q) : niveau %q horodaté %v, attendu %q %v", tt.line, r.Level, r.HasTime, tt.level, tt.hasTime)
+		}
+	}
+
+	// Une ligne sans horodatage hérite de la précédente
+	recs := parseLogLines([]string{"2026-02-27 11:33:20 ERROR panique", "goroutine 1 [running]:"})
+	if !recs[1].HasTime || !recs[1].Time.Equal(recs[0].Time) || recs[1].Level != "ERROR" {
+		t.Errorf("suite de message : %+v", recs[1])
+	}
+}
+
+func TestParseUserTime(t *testing.T) {
+	paris := time.FixedZone("CET", 3600)
+	withLocal(t, paris)
+	tests := []struct {
+		in   string
+		want time.Time
+		ok   bool
+	}{
+		{"", time.Time{}, false},
+		{"2026-02-27", time.Date(2026, 2, 27, 0, 0, 0, 0, paris), true},
+		{"2026-02-27 11:33", time.Date(2026, 2, 27, 11, 33, 0, 0, paris), true},
+		{" 2026-02-27T11:33 ", time.Date(2026, 2, 27, 11, 33, 0, 0, paris), true},
+		{"2026-02-27 11:33:20", time.Date(2026, 2, 27, 11, 33, 20, 0, paris), true},
+		{"2026-02-27T11:33:20Z", time.Date(2026, 2, 27, 11, 33, 20, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		got, ok, err := parseUserTime(tt.in)
+		if err != nil || ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("parseUserTime(%q) = %v, %v, %v ; attendu %v, %v", tt.in, got, ok, err, tt.want, tt.ok)
+		}
+	}
+	if _, _, err := parseUserTime("27/02/2026"); err == nil {
+		t.Error("27/02/2026 : erreur attendue")
+	}
+}
+
+// Les bornes saisies et les horodatages sans fuseau du log désignent la même heure locale
+func TestLogFilter(t *testing.T) {
+	withLocal(t, time.FixedZone("CET", 3600))
+	recs := parseLogLines([]string{
+		"2026-02-27 11:32:59 INFO avant",
+		"2026-02-27 11:33:00 INFO début",
+		"2026-02-27 11:33:30 ERROR milieu",
+		"    suite du message",
+		"2026-02-27T10:34:00Z WARN fin (11:34 heure locale)",
+		"2026-02-27 11:34:01 INFO après",
+		"ligne sans horodatage (hérite de 11:34:01)",
+	})
+	from, _, _ := parseUserTime("2026-02-27 11:33")
+	to, _, _ := parseUserTime("2026-02-27 11:34")
+	tests := []struct {
+		name   string
+		filter logFilter
+		want   []int
+	}{
+		{"sans filtre", logFilter{}, []int{0, 1, 2, 3, 4, 5, 6}},
+		{"depuis", logFilter{From: from, HasFrom: true}, []int{1, 2, 3, 4, 5, 6}},
+		{"jusqu'à", logFilter{To: to, HasTo: true}, []int{0, 1, 2, 3, 4}},
+		{"période", logFilter{From: from, HasFrom: true, To: to, HasTo: true}, []int{1, 2, 3, 4}},
+		{"niveaux", logFilter{Levels: map[string]bool{"ERROR": true, "WARN": true}}, []int{2, 3, 4}},
+		{"période et niveau", logFilter{From: from, HasFrom: true, To: to, HasTo: true, Levels: map[string]bool{"INFO": true}}, []int{1}},
+	}
+	for _, tt := range tests {
+		var got []int
+		for i, r := range recs {
+			if tt.filter.keep(r) {
+				got = append(got, i)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s : lignes %v, attendu %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s : lignes %v, attendu %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestLogBucket(t *testing.T) {
+	// Fuseau à la demi-heure : Truncate(time.Hour) donnerait des tranches commençant à :30
+	india := time.FixedZone("IST", 5*3600+1800)
+	withLocal(t, india)
+	at := func(h, m, s, ns int) time.Time { return time.Date(2026, 2, 27, h, m, s, ns, india) }
+	tests := []struct {
+		t      time.Time
+		bucket time.Duration
+		want   time.Time
+	}{
+		{at(11, 33, 20, 0), time.Minute, at(11, 33, 0, 0)},
+		{at(11, 33, 20, 0), time.Hour, at(11, 0, 0, 0)},
+		{at(11, 59, 59, 999999999), time.Minute, at(11, 59, 0, 0)},
+		{at(11, 59, 59, 999999999), time.Hour, at(11, 0, 0, 0)},
+		{at(12, 0, 0, 0), time.Hour, at(12, 0, 0, 0)},
+		{at(0, 0, 0, 0), time.Hour, at(0, 0, 0, 0)},
+		{at(23, 30, 0, 0), time.Hour, at(23, 0, 0, 0)},
+	}
+	for _, tt := range tests {
+		got := logBucket(tt.t, tt.bucket)
+		if !got.Equal(tt.want) || got.Location() != tt.t.Location() {
+			t.Errorf("logBucket(%v, %v) = %v, attendu %v", tt.t, tt.bucket, got, tt.want)
+		}
+	}
+
+	// Une ligne de 11:33 locale tombe dans la tranche 11:33 du log, quel que soit le fuseau
+	withLocal(t, time.FixedZone("CET", 3600))
+	r := parseLogLine("2026-02-27 11:33:20 INFO ok")
+	if got := logBucket(r.Time, time.Minute).Format("15:04"); got != "11:33" {
+		t.Errorf("tranche %s, attendu 11:33", got)
+	}
+}
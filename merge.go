@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ------- merged.txt : en-têtes et marqueurs de provenance --------
+// Chaque fichier peut être précédé d'un séparateur et d'un en-tête lisible (merge_separator, merge_header),
+// ses lignes peuvent être numérotées (merge_line_numbers), et des marqueurs de début / fin
+// (merge_markers) permettent de reconstruire les fichiers d'origine avec la commande de découpage.
+
+// Préfixe des marqueurs de provenance
+const mergeMarker = "#==> fileops:"
+
+// Marqueurs : #==> fileops:begin path="..." size=N mtime=... numbered=0 <==  /  #==> fileops:end noeol=0 <==
+var (
+	mergeBeginRe = regexp.MustCompile(`^#==> fileops:begin path=("(?:[^"\\]|\\.)*") size=(\d+) mtime=(\S+) numbered=([01]) <==$`)
+	mergeEndRe   = regexp.MustCompile(`^#==> fileops:end noeol=([01]) <==$`)
+)
+
+// Ligne de contenu qui ressemble à un marqueur, déjà échappée ou non : avec les marqueurs, un ">" est
+// ajouté devant à la fusion et retiré au découpage (comme les lignes "From " des boîtes mail)
+var (
+	mergeMarkerLineRe = regexp.MustCompile(`^>*#==> fileops:`)
+	mergeEscapedRe    = regexp.MustCompile(`^>+#==> fileops:`)
+)
+
+// Largeur du préfixe de numéro de ligne : "     1| "
+const mergeLineNumberWidth = 6
+
+// Remplace {path}, {size}, {mtime} et {lines} dans un en-tête / séparateur
+func expandMergeTemplate(tpl string, r fileResult) string {
+	return strings.NewReplacer(
+		"{path}", r.Entry.Path,
+		"{size}", strconv.FormatInt(r.Entry.Size, 10),
+		"{mtime}", r.Entry.ModTime.Format(time.RFC3339),
+		"{lines}", strconv.Itoa(r.Lines),
+		`\n`, "\n",
+	).Replace(tpl)
+}
+
+// Ajoute un fichier à merged.txt selon les options de fusion
+func writeMergedEntry(merged *textOutput, cfg Config, r fileResult, first bool) error {
+	var sb strings.Builder
+
+	// Séparateur entre deux fichiers, puis en-tête lisible
+	if !first && cfg.MergeSeparator != "" {
+		sb.WriteString(expandMergeTemplate(cfg.MergeSeparator, r) + "\n")
+	}
+	if cfg.MergeHeader != "" {
+		sb.WriteString(expandMergeTemplate(cfg.MergeHeader, r) + "\n")
+	}
+
+	if cfg.MergeMarkers {
+		fmt.Fprintf(&sb, "%sbegin path=%s size=%d mtime=%s numbered=%s <==\n",
+			mergeMarker, strconv.Quote(r.Entry.Path), r.Entry.Size,
+			r.Entry.ModTime.Format(time.RFC3339Nano), boolFlag(cfg.MergeLineNumbers))
+	}
+
+	// Contenu, éventuellement numéroté (une ligne numérotée ne peut pas être prise pour un marqueur)
+	text := r.Text
+	if cfg.MergeLineNumbers {
+		text = numberLines(text)
+	} else if cfg.MergeMarkers {
+		text = escapeMergeMarkers(text)
+	}
+	sb.WriteString(text)
+
+	// Le fichier doit se terminer par un '\n' ; avec les marqueurs on note s'il a été ajouté
+	noeol := !strings.HasSuffix(text, "\n")
+	if cfg.MergeMarkers {
+		if noeol {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "%send noeol=%s <==\n", mergeMarker, boolFlag(noeol))
+	} else {
+		sb.WriteString("\n")
+	}
+
+	_, err := merged.WriteString(sb.String())
+	return err
+}
+
+// "1" ou "0" pour les attributs des marqueurs
+func boolFlag(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// Échappe les lignes du contenu qui ressemblent à un marqueur
+func escapeMergeMarkers(text string) string {
+	if !strings.Contains(text, mergeMarker) {
+		return text
+	}
+	lines := strings.SplitAfter(text, "\n")
+	for i, l := range lines {
+		if mergeMarkerLineRe.MatchString(l) {
+			lines[i] = ">" + l
+		}
+	}
+	return strings.Join(lines, "")
+}
+
+// Préfixe chaque ligne par son numéro
+func numberLines(text string) string {
+	if text == "" {
+		return ""
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	var sb strings.Builder
+	for i, l := range lines {
+		fmt.Fprintf(&sb, "%*d| %s", mergeLineNumberWidth, i+1, l)
+	}
+	return sb.String()
+}
+
+// Retire le préfixe "     N| " d'une ligne numérotée
+func stripLineNumber(line string) string {
+	if i := strings.Index(line, "| "); i >= 0 && i <= mergeLineNumberWidth+2 {
+		if _, err := strconv.Atoi(strings.TrimSpace(line[:i])); err == nil {
+			return line[i+2:]
+		}
+	}
+	return line
+}
+
+// Fichier reconstruit à partir de merged.txt
+type splitFile struct {
+	Path    string
+	ModTime time.Time
+	Content string
+}
+
+// Lit les fichiers délimités par les marqueurs de provenance. Les lignes sont lues avec leur fin
+// ("\n" ou "\r\n") et sans limite de longueur : le contenu est rendu octet pour octet.
+func parseMerged(text string) ([]splitFile, error) {
+	var files []splitFile
+	var cur *splitFile
+	var body strings.Builder
+	numbered := false
+
+	r := bufio.NewReader(strings.NewReader(text))
+	lineNo := 0
+	for {
+		raw, err := r.ReadString('\n')
+		if raw == "" && err != nil {
+			break
+		}
+		lineNo++
+		// Les marqueurs sont comparés sans la fin de ligne
+		line := strings.TrimSuffix(strings.TrimSuffix(raw, "\n"), "\r")
+
+		if cur == nil {
+			// Hors d'un fichier : seuls les marqueurs de début comptent (séparateurs, en-têtes ignorés)
+			m := mergeBeginRe.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			p, err := strconv.Unquote(m[1])
+			if err != nil {
+				return files, fmt.Errorf("ligne %d : chemin invalide", lineNo)
+			}
+			mtime, _ := time.Parse(time.RFC3339Nano, m[3])
+			cur = &splitFile{Path: p, ModTime: mtime}
+			numbered = m[4] == "1"
+			body.Reset()
+			continue
+		}
+
+		if m := mergeEndRe.FindStringSubmatch(line); m != nil {
+			cur.Content = body.String()
+			if m[1] == "1" {
+				cur.Content = strings.TrimSuffix(cur.Content, "\n")
+			}
+			files = append(files, *cur)
+			cur = nil
+			continue
+		}
+		if numbered {
+			raw = stripLineNumber(raw)
+		} else if mergeEscapedRe.MatchString(raw) {
+			raw = raw[1:]
+		}
+		body.WriteString(raw)
+	}
+	if cur != nil {
+		return files, fmt.Errorf("marqueur de fin manquant pour %s", cur.Path)
+	}
+	return files, nil
+}
+
+// Chemin de sortie sûr : sans lecteur, ni chemin absolu, ni ".." qui sortirait du dossier
+func safeRelPath(p string) string {
+	p = filepath.ToSlash(p)
+	if v := filepath.VolumeName(p); v != "" {
+		p = p[len(v):]
+	}
+	var parts []string
+	for _, part := range strings.Split(p, "/") {
+		if part == "" || part == "." || part == ".." {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return filepath.Join(parts...)
+}
+
+// Commande de découpage : reconstruit les fichiers d'un merged.txt avec marqueurs
+func choixSplit(cfg Config, reader *bufio.Reader) {
+	fmt.Printf("Fichier fusionné (ENTER = %s) : ", filepath.Join(cfg.OutDir, "merged.txt"))
+	src, _ := reader.ReadString('\n')
+	src = strings.TrimSpace(src)
+	if src == "" {
+		src = filepath.Join(cfg.OutDir, "merged.txt")
+	}
+	fmt.Printf("Dossier de destination (ENTER = %s) : ", filepath.Join(cfg.OutDir, "split"))
+	dest, _ := reader.ReadString('\n')
+	dest = strings.TrimSpace(dest)
+	if dest == "" {
+		dest = filepath.Join(cfg.OutDir, "split")
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		fmt.Println("Erreur lecture :", err)
+		return
+	}
+	text, _ := decodeText(data)
+	files, err := parseMerged(text)
+	if err != nil {
+		fmt.Println("Erreur découpage :", err)
+	}
+	if len(files) == 0 {
+		fmt.Println("Aucun marqueur de provenance trouvé (activer merge_markers avant la fusion).")
+		return
+	}
+
+	for _, f := range files {
+		rel := safeRelPath(f.Path)
+		if rel == "" {
+			fmt.Println("Chemin ignoré :", f.Path)
+			continue
+		}
+		out := filepath.Join(dest, rel)
+		if err := os.MkdirAll(filepath.Dir(out), os.ModePerm); err != nil {
+			fmt.Println("Erreur création dossier :", err)
+			continue
+		}
+		if err := writeTextFile(out, f.Content, cfg.OutputEncoding); err != nil {
+			fmt.Println("Erreur écriture :", err)
+			continue
+		}
+		if !f.ModTime.IsZero() {
+			os.Chtimes(out, f.ModTime, f.ModTime)
+		}
+		fmt.Println("Reconstruit :", out)
+	}
+	fmt.Println("Fichiers reconstruits :", len(files))
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Fusionne les contenus avec les options données puis relit merged.txt comme la commande de découpage
+func mergeAndSplit(t *testing.T, cfg Config, contents []string) []splitFile {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "merged.txt")
+	out, err := createTextOutput(path, encUTF8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2026, 2, 27, 11, 30, 0, 123456789, time.UTC)
+	for i, c := range contents {
+		r := fileResult{
+			Entry: scanEntry{Path: filepath.Join("data", "f"+string(rune('a'+i))+".txt"), Size: int64(len(c)), ModTime: mtime},
+			Text:  c,
+			Lines: countLines(c),
+		}
+		if err := writeMergedEntry(out, cfg, r, i == 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, _ := decodeText(data)
+	files, err := parseMerged(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(contents) {
+		t.Fatalf("%d fichier(s) relu(s), attendu %d\n%s", len(files), len(contents), text)
+	}
+	for _, f := range files {
+		if !f.ModTime.Equal(mtime) {
+			t.Errorf("%s : date %v, attendu %v", f.Path, f.ModTime, mtime)
+		}
+	}
+	return files
+}
+
+func TestMergeSplitRoundTrip(t *testing.T) {
+	contents := []string{
+		"une ligne\ndeux lignes\n",
+		"sans fin de ligne",
+		"",
+		"\n\n",
+		"fin CRLF\r\ndeuxième\r\n",
+		"CRLF sans fin\r\nderniere",
+		"retour chariot seul\r",
+		strings.Repeat("x", 200*1024) + "\nligne longue au-dessus\n",
+		"#==> fileops:end noeol=0 <==\nle contenu continue\n",
+		"#==> fileops:begin path=\"faux.txt\" size=1 mtime=2026-01-01T00:00:00Z numbered=0 <==\n",
+		">#==> fileops:déjà échappé\n>>#==> fileops:deux fois",
+		"     1| ressemble à une ligne numérotée\n",
+	}
+	options := map[string]Config{
+		"marqueurs":           {MergeMarkers: true},
+		"numéros":             {MergeMarkers: true, MergeLineNumbers: true},
+		"en-tête+séparateur":  {MergeMarkers: true, MergeHeader: "=== {path} ({lines} lignes) ===", MergeSeparator: "----"},
+		"numéros+en-tête+sép": {MergeMarkers: true, MergeLineNumbers: true, MergeHeader: "=== {path} ===", MergeSeparator: "\\n----"},
+	}
+	for name, cfg := range options {
+		t.Run(name, func(t *testing.T) {
+			files := mergeAndSplit(t, cfg, contents)
+			for i, f := range files {
+				if f.Content != contents[i] {
+					t.Errorf("fichier %d (%s) : %.60q, attendu %.60q", i, f.Path, f.Content, contents[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseMergedErrors(t *testing.T) {
+	tests := []struct {
+		name, text string
+		files      int
+	}{
+		{"sans marqueurs", "du texte\nsans marqueurs\n", 0},
+		{"fin manquante", "#==> fileops:begin path=\"a.txt\" size=1 mtime=2026-01-01T00:00:00Z numbered=0 <==\nx\n", 0},
+		{"chemin invalide", "#==> fileops:begin path=\"a\\q\" size=1 mtime=2026-01-01T00:00:00Z numbered=0 <==\n", 0},
+	}
+	for _, tt := range tests {
+		files, err := parseMerged(tt.text)
+		if len(files) != tt.files {
+			t.Errorf("%s : %d fichier(s), attendu %d", tt.name, len(files), tt.files)
+		}
+		if tt.name != "sans marqueurs" && err == nil {
+			t.Errorf("%s : erreur attendue", tt.name)
+		}
+	}
+}
+
+func TestEscapeMergeMarkers(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"rien à échapper\n", "rien à échapper\n"},
+		{"#==> fileops:end noeol=0 <==\n", ">#==> fileops:end noeol=0 <==\n"},
+		{"a\n>#==> fileops:x", "a\n>>#==> fileops:x"},
+		{"texte #==> fileops: au milieu\n", "texte #==> fileops: au milieu\n"},
+	}
+	for _, tt := range tests {
+		if got := escapeMergeMarkers(tt.in); got != tt.want {
+			t.Errorf("escapeMergeMarkers(%q) = %q, attendu %q", tt.in, got, tt.want)
+		}
+	}
+}
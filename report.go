@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ------- Rapports --------
+// Les résultats sont décrits par des tables (colonnes + lignes de valeurs typées) et écrits
+// dans le format choisi par la clé report_format ou le flag -format : text, json, csv, markdown.
+
+// Table de rapport
+type reportTable struct {
+	Name    string // nom de la table : clé JSON, titre Markdown / texte, suffixe du fichier CSV
+	Columns []string
+	Rows    [][]any
+}
+
+// Format de rapport
+type reportWriter interface {
+	Ext() string
+	Write(w io.Writer, tables []reportTable) error
+}
+
+// Formats disponibles
+var reportWriters = map[string]reportWriter{
+	"text":     textReport{},
+	"json":     jsonReport{},
+	"csv":      csvReport{},
+	"markdown": markdownReport{},
+}
+
+// Normalise le nom de format ("md" -> "markdown", "txt" -> "text"), "" si inconnu
+func normalizeReportFormat(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "text", "txt":
+		return "text"
+	case "json":
+		return "json"
+	case "csv":
+		return "csv"
+	case "md", "markdown":
+		return "markdown"
+	}
+	return ""
+}
+
+// Vérifie le format de la config, texte si inconnu
+func checkReportFormat(name string) string {
+	f := normalizeReportFormat(name)
+	if f == "" {
+		fmt.Println("Format de rapport inconnu :", name, "- text utilisé.")
+		return "text"
+	}
+	return f
+}
+
+// Écrit les tables dans OutDir/base.<ext>, en CSV une table par fichier (base_<table>.csv)
+// Retourne les chemins des fichiers écrits
+func writeReport(cfg Config, base string, tables []reportTable) ([]string, error) {
+	w := reportWriters[checkReportFormat(cfg.ReportFormat)]
+
+	groups := [][]reportTable{tables}
+	names := []string{base}
+	if _, ok := w.(csvReport); ok && len(tables) > 1 {
+		groups, names = nil, nil
+		for _, t := range tables {
+			groups = append(groups, []reportTable{t})
+			names = append(names, base+"_"+t.Name)
+		}
+	}
+
+	var written []string
+	for i, g := range groups {
+		var buf bytes.Buffer
+		if err := w.Write(&buf, g); err != nil {
+			return written, err
+		}
+		p := filepath.Join(cfg.OutDir, names[i]+w.Ext())
+		if err := writeTextFile(p, buf.String(), cfg.OutputEncoding); err != nil {
+			return written, err
+		}
+		written = append(written, p)
+	}
+	return written, nil
+}
+
+// Valeur affichée dans les formats texte (dates en RFC3339, flottants à 2 décimales)
+func formatCell(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if x.IsZero() {
+			return ""
+		}
+		return x.Format(time.RFC3339)
+	case float64:
+		return fmt.Sprintf("%.2f", x)
+	case string:
+		return x
+	}
+	return fmt.Sprint(v)
+}
+
+// Taille lisible : 512 o, 1.5 Ko, 12.3 Mo...
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d o", n)
+	}
+	v, i := float64(n)/unit, 0
+	for v >= unit && i < 3 {
+		v /= unit
+		i++
+	}
+	return fmt.Sprintf("%.1f %s", v, []string{"Ko", "Mo", "Go", "To"}[i])
+}
+
+// Valeur JSON (dates en RFC3339, le reste tel quel)
+func jsonCell(v any) any {
+	if t, ok := v.(time.Time); ok {
+		if t.IsZero() {
+			return nil
+		}
+		return t.Format(time.RFC3339)
+	}
+	return v
+}
+
+// --- JSON : {"table": [{"colonne": valeur, ...}, ...], ...} en gardant l'ordre des colonnes ---
+
+type jsonReport struct{}
+
+func (jsonReport) Ext() string { return ".json" }
+
+func (jsonReport) Write(w io.Writer, tables []reportTable) error {
+	var buf bytes.Buffer
+	buf.WriteString("{\n")
+	for ti, t := range tables {
+		key, _ := json.Marshal(t.Name)
+		fmt.Fprintf(&buf, "  %s: [", key)
+		for ri, row := range t.Rows {
+			if ri > 0 {
+				buf.WriteString(",")
+			}
+			buf.WriteString("\n    {")
+			for ci, col := range t.Columns {
+				k, _ := json.Marshal(col)
+				var cell any
+				if ci < len(row) {
+					cell = jsonCell(row[ci])
+				}
+				v, err := json.Marshal(cell)
+				if err != nil {
+					return err
+				}
+				if ci > 0 {
+					buf.WriteString(", ")
+				}
+				fmt.Fprintf(&buf, "%s: %s", k, v)
+			}
+			buf.WriteString("}")
+		}
+		if len(t.Rows) > 0 {
+			buf.WriteString("\n  ")
+		}
+		buf.WriteString("]")
+		if ti < len(tables)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// --- CSV : une ligne d'en-tête puis une ligne par enregistrement ---
+
+type csvReport struct{}
+
+func (csvReport) Ext() string { return ".csv" }
+
+func (csvReport) Write(w io.Writer, tables []reportTable) error {
+	cw := csv.NewWriter(w)
+	for i, t := range tables {
+		if i > 0 {
+			cw.Write(nil)
+		}
+		cw.Write(t.Columns)
+		for _, row := range t.Rows {
+			rec := make([]string, len(row))
+			for j, v := range row {
+				rec[j] = formatCell(v)
+			}
+			cw.Write(rec)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// --- Markdown : un titre et un tableau par table ---
+
+type markdownReport struct{}
+
+func (markdownReport) Ext() string { return ".md" }
+
+func (markdownReport) Write(w io.Writer, tables []reportTable) error {
+	var buf bytes.Buffer
+	for i, t := range tables {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "## %s\n\n", t.Name)
+		buf.WriteString("| " + strings.Join(t.Columns, " | ") + " |\n")
+		buf.WriteString("|" + strings.Repeat(" --- |", len(t.Columns)) + "\n")
+		for _, row := range t.Rows {
+			cells := make([]string, len(row))
+			for j, v := range row {
+				// "|" casserait le tableau
+				cells[j] = strings.ReplaceAll(formatCell(v), "|", "\\|")
+			}
+			buf.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// --- Texte : colonnes alignées ---
+
+type textReport struct{}
+
+func (textReport) Ext() string { return ".txt" }
+
+func (textReport) Write(w io.Writer, tables []reportTable) error {
+	var buf bytes.Buffer
+	for i, t := range tables {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "=== %s ===\n", t.Name)
+
+		// Largeur de chaque colonne (en caractères, pas en octets)
+		cells := make([][]string, len(t.Rows))
+		widths := make([]int, len(t.Columns))
+		for j, c := range t.Columns {
+			widths[j] = utf8.RuneCountInString(c)
+		}
+		for r, row := range t.Rows {
+			cells[r] = make([]string, len(row))
+			for j, v := range row {
+				cells[r][j] = formatCell(v)
+				if j < len(widths) && utf8.RuneCountInString(cells[r][j]) > widths[j] {
+					widths[j] = utf8.RuneCountInString(cells[r][j])
+				}
+			}
+		}
+
+		line := func(vals []string) {
+			var sb strings.Builder
+			for j, v := range vals {
+				if j > 0 {
+					sb.WriteString(" | ")
+				}
+				sb.WriteString(v + strings.Repeat(" ", widths[j]-utf8.RuneCountInString(v)))
+			}
+			buf.WriteString(strings.TrimRight(sb.String(), " ") + "\n")
+		}
+		line(t.Columns)
+		sep := make([]string, len(widths))
+		for j, wd := range widths {
+			sep[j] = strings.Repeat("-", wd)
+		}
+		line(sep)
+		for _, row := range cells {
+			line(row)
+		}
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// --- Enregistrement par fichier (choix A et B) ---
+
+// Statistiques d'un fichier analysé
+type fileRecord struct {
+	Path           string
+	Size           int64
+	ModTime        time.Time
+	Lines          int
+	Words          int
+	AvgWordLen     float64
+	Encoding       string
+	Language       string
+	LangConfidence float64
+	SHA256         string
+	Error          string
+}
+
+// Colonnes de la table des fichiers
+var fileRecordColumns = []string{"path", "size", "mtime", "lines", "words", "avg_word_len",
+	"encoding", "language", "language_confidence", "sha256", "error"}
+
+// Table "files" à partir des enregistrements
+func fileRecordsTable(records []fileRecord) reportTable {
+	t := reportTable{Name: "files", Columns: fileRecordColumns}
+	for _, r := range records {
+		t.Rows = append(t.Rows, []any{r.Path, r.Size, r.ModTime, r.Lines, r.Words, r.AvgWordLen,
+			r.Encoding, r.Language, r.LangConfidence, r.SHA256, r.Error})
+	}
+	return t
+}
+
+// Table de l'index du choix B : chemin, taille et date de chaque fichier
+func indexTable(records []fileRecord) reportTable {
+	t := reportTable{Name: "index", Columns: []string{"path", "size", "mtime"}}
+	for _, r := range records {
+		t.Rows = append(t.Rows, []any{r.Path, r.Size, r.ModTime})
+	}
+	return t
+}
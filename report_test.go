@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Table aux valeurs piégeuses : séparateurs, guillemets, retour à la ligne, accents, date nulle
+func sampleTable() reportTable {
+	mtime := time.Date(2026, 2, 27, 11, 33, 20, 0, time.UTC)
+	return indexTable([]fileRecord{
+		{Path: "a.txt", Size: 12, ModTime: mtime},
+		{Path: `dossier, "cité"/b|c.txt`, Size: 1 << 20, ModTime: mtime},
+		{Path: "ligne\nsuivante/été.txt", Size: 0},
+	})
+}
+
+func TestNormalizeReportFormat(t *testing.T) {
+	tests := map[string]string{"": "text", "TXT": "text", " json ": "json", "csv": "csv", "md": "markdown", "Markdown": "markdown", "xml": ""}
+	for in, want := range tests {
+		if got := normalizeReportFormat(in); got != want {
+			t.Errorf("normalizeReportFormat(%q) = %q, attendu %q", in, got, want)
+		}
+	}
+}
+
+func TestIndexTable(t *testing.T) {
+	tab := sampleTable()
+	if tab.Name != "index" || strings.Join(tab.Columns, " ") != "path size mtime" {
+		t.Fatalf("table %q, colonnes %v", tab.Name, tab.Columns)
+	}
+	if len(tab.Rows) != 3 || tab.Rows[1][0] != `dossier, "cité"/b|c.txt` || tab.Rows[1][1] != int64(1<<20) {
+		t.Errorf("lignes %v", tab.Rows)
+	}
+}
+
+// JSON relu : mêmes valeurs, dates en RFC3339, date nulle à null, colonnes dans l'ordre
+func TestJSONReportRoundTrip(t *testing.T) {
+	tab := sampleTable()
+	var buf bytes.Buffer
+	if err := (jsonReport{}).Write(&buf, []reportTable{tab, {Name: "vide", Columns: []string{"x"}}}); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string][]map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("JSON invalide : %v\n%s", err, buf.String())
+	}
+	if len(got["index"]) != 3 || got["vide"] == nil || len(got["vide"]) != 0 {
+		t.Fatalf("tables relues : %v", got)
+	}
+	for i, row := range got["index"] {
+		if row["path"] != tab.Rows[i][0] || row["size"] != float64(tab.Rows[i][1].(int64)) {
+			t.Errorf("ligne %d : %v", i, row)
+		}
+	}
+	if got["index"][0]["mtime"] != "2026-02-27T11:33:20Z" || got["index"][2]["mtime"] != nil {
+		t.Errorf("dates : %v, %v", got["index"][0]["mtime"], got["index"][2]["mtime"])
+	}
+	line := strings.Split(buf.String(), "\n")[2]
+	if !(strings.Index(line, `"path"`) < strings.Index(line, `"size"`) && strings.Index(line, `"size"`) < strings.Index(line, `"mtime"`)) {
+		t.Errorf("ordre des colonnes : %s", line)
+	}
+}
+
+// CSV relu : en-tête puis les valeurs, guillemets et retours à la ligne compris
+func TestCSVReportRoundTrip(t *testing.T) {
+	tab := sampleTable()
+	var buf bytes.Buffer
+	if err := (csvReport{}).Write(&buf, []reportTable{tab}); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"path", "size", "mtime"},
+		{"a.txt", "12", "2026-02-27T11:33:20Z"},
+		{`dossier, "cité"/b|c.txt`, "1048576", "2026-02-27T11:33:20Z"},
+		{"ligne\nsuivante/été.txt", "0", ""},
+	}
+	if len(recs) != len(want) {
+		t.Fatalf("%d lignes relues, attendu %d", len(recs), len(want))
+	}
+	for i := range want {
+		if strings.Join(recs[i], "\x00") != strings.Join(want[i], "\x00") {
+			t.Errorf("ligne %d : %q, attendu %q", i, recs[i], want[i])
+		}
+	}
+}
+
+func TestMarkdownReport(t *testing.T) {
+	tab := reportTable{Name: "index", Columns: []string{"path", "size"}, Rows: [][]any{{"a|b.txt", 3}, {"c.txt", 1.5}}}
+	var buf bytes.Buffer
+	if err := (markdownReport{}).Write(&buf, []reportTable{tab, tab}); err != nil {
+		t.Fatal(err)
+	}
+	table := "## index\n\n| path | size |\n| --- | --- |\n| a\\|b.txt | 3 |\n| c.txt | 1.50 |\n"
+	if want := table + "\n" + table; buf.String() != want {
+		t.Errorf("markdown :\n%s\nattendu :\n%s", buf.String(), want)
+	}
+}
+
+// Colonnes alignées en caractères, pas en octets
+func TestTextReport(t *testing.T) {
+	tab := reportTable{Name: "index", Columns: []string{"path", "size"}, Rows: [][]any{{"été.txt", 3}, {"a", int64(12345)}}}
+	var buf bytes.Buffer
+	if err := (textReport{}).Write(&buf, []reportTable{tab}); err != nil {
+		t.Fatal(err)
+	}
+	want := "=== index ===\npath    | size\n------- | -----\nété.txt | 3\na       | 12345\n"
+	if buf.String() != want {
+		t.Errorf("texte :\n%s\nattendu :\n%s", buf.String(), want)
+	}
+}
+
+// Un fichier par rapport, un fichier par table en CSV
+func TestWriteReport(t *testing.T) {
+	files := fileRecordsTable([]fileRecord{{Path: "a.txt", Lines: 2, Words: 3, Encoding: "utf-8", Language: "fr"}})
+	tables := []reportTable{files, sampleTable()}
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"", []string{"report.txt"}},
+		{"json", []string{"report.json"}},
+		{"md", []string{"report.md"}},
+		{"csv", []string{"report_files.csv", "report_index.csv"}},
+		{"inconnu", []string{"report.txt"}},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		written, err := writeReport(Config{OutDir: dir, ReportFormat: tt.format, OutputEncoding: encUTF8}, "report", tables)
+		if err != nil {
+			t.Fatalf("%s : %v", tt.format, err)
+		}
+		if len(written) != len(tt.want) {
+			t.Fatalf("%s : fichiers %v, attendu %v", tt.format, written, tt.want)
+		}
+		for i, p := range written {
+			if p != filepath.Join(dir, tt.want[i]) {
+				t.Errorf("%s : fichier %s, attendu %s", tt.format, p, tt.want[i])
+			}
+			data, err := os.ReadFile(p)
+			if err != nil || !bytes.Contains(data, []byte("a.txt")) {
+				t.Errorf("%s : %s illisible ou incomplet (%v)", tt.format, p, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ------- Analyse multi-fichiers en parallèle --------
+// Les fichiers sont analysés par un groupe de workers de taille fixe (clé concurrency),
+// les résultats sont rendus dans l'ordre de la liste pour que report, index
+// et merged.txt restent identiques d'une exécution à l'autre.
+
+// Fichier ou membre d'archive à analyser
+type scanEntry struct {
+	Path    string    // chemin affiché (pour un membre : chemin de l'archive + chemin interne)
+	Size    int64     // taille sur disque (compressée pour un .gz) ou taille du membre
+	ModTime time.Time // date de modification
+	open    func() (io.ReadCloser, error)
+}
+
+// Lit le contenu brut de l'entrée (décompressé)
+func (e scanEntry) read() ([]byte, error) {
+	rc, err := e.open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// Nombre de lignes d'un texte, la dernière comptant même sans retour à la ligne final
+// (même règle pour le choix A et le choix B)
+func countLines(text string) int {
+	n := strings.Count(text, "\n")
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		n++
+	}
+	return n
+}
+
+// Compte les mots (en ignorant les valeurs numériques) et leur longueur totale
+func countWords(text string) (int, int) {
+	words, totalLen := 0, 0
+	for _, w := range strings.Fields(text) {
+		if _, err := strconv.Atoi(w); err != nil {
+			words++
+			totalLen += len(w)
+		}
+	}
+	return words, totalLen
+}
+
+// Résultat de l'analyse d'un fichier
+type fileResult struct {
+	Entry    scanEntry
+	Text     string // contenu décodé, utilisé pour merged.txt
+	Encoding string
+	Lines    int
+	Words    int
+	WordLen  int // longueur totale des mots
+	SHA256   string
+	Lang     langResult
+	Freq     map[string]int // fréquence des mots, calculée seulement pour le rapport HTML
+	Cached   bool           // statistiques reprises du cache de scan
+	Err      error
+}
+
+// Enregistrement du rapport pour ce fichier
+func (r fileResult) record() fileRecord {
+	rec := fileRecord{
+		Path:     r.Entry.Path,
+		Size:     r.Entry.Size,
+		ModTime:  r.Entry.ModTime,
+		Lines:    r.Lines,
+		Words:    r.Words,
+		Encoding: r.Encoding,
+		Language: r.Lang.Lang,
+		SHA256:   r.SHA256,
+	}
+	if r.Words > 0 {
+		rec.AvgWordLen = float64(r.WordLen) / float64(r.Words)
+	}
+	if r.Lang.Lang != langUnknown {
+		rec.LangConfidence = r.Lang.Confidence
+	}
+	if r.Err != nil {
+		rec.Error = r.Err.Error()
+	}
+	return rec
+}
+
+// Empreinte SHA-256 en hexadécimal
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Lit et analyse un fichier (appelé par les workers) ; freq = calculer la fréquence des mots
+func analyzeEntry(e scanEntry, freq bool) fileResult {
+	res := fileResult{Entry: e}
+	data, err := e.read()
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	return analyzeData(e, data, sha256Hex(data), freq)
+}
+
+// Analyse le contenu brut d'une entrée dont l'empreinte sum est déjà calculée
+func analyzeData(e scanEntry, data []byte, sum string, freq bool) fileResult {
+	res := fileResult{Entry: e, SHA256: sum}
+	res.Text, res.Encoding = decodeText(data)
+
+	res.Lines = countLines(res.Text)
+	res.Words, res.WordLen = countWords(res.Text)
+	res.Lang = detectLanguage(res.Text)
+	if freq {
+		res.Freq = wordFrequencies(res.Text, res.Lang.Lang)
+	}
+	return res
+}
+
+// Analyse les entrées avec au plus workers goroutines et appelle emit dans l'ordre des entrées
+// Au plus 2*workers résultats attendent en mémoire d'être écrits
+func scanEntries(entries []scanEntry, workers int, analyze func(scanEntry) fileResult, emit func(fileResult)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	// Un canal par entrée pour récupérer son résultat dans l'ordre
+	results := make([]chan fileResult, len(entries))
+	for i := range results {
+		results[i] = make(chan fileResult, 1)
+	}
+
+	jobs := make(chan int)
+	window := make(chan struct{}, 2*workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] <- analyze(entries[i])
+			}
+		}()
+	}
+
+	// Distribution des tâches, limitée par la fenêtre
+	go func() {
+		for i := range entries {
+			window <- struct{}{}
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	// Écriture dans l'ordre, chaque résultat écrit libère une place dans la fenêtre
+	for i := range entries {
+		emit(<-results[i])
+		<-window
+	}
+	wg.Wait()
+}
+
+// Nombre d'occurrences d'un mot
+type wordCount struct {
+	Word  string
+	Count int
+}
+
+// Fréquence des mots d'un texte : en minuscules, sans ponctuation ni nombres,
+// d'au moins 3 lettres et hors mots outils de la langue
+func wordFrequencies(text, lang string) map[string]int {
+	freq := make(map[string]int)
+	stop := stopWords[lang]
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-' && r != '\''
+	}) {
+		w = strings.Trim(w, "-'")
+		if utf8.RuneCountInString(w) < 3 || stop[w] {
+			continue
+		}
+		freq[w]++
+	}
+	return freq
+}
+
+// Les n mots les plus fréquents (à égalité, ordre alphabétique)
+func topWords(freq map[string]int, n int) []wordCount {
+	list := make([]wordCount, 0, len(freq))
+	for w, c := range freq {
+		list = append(list, wordCount{w, c})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Word < list[j].Word
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
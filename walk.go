@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ------- Parcours d'arborescence --------
+// Parcours basé sur filepath.WalkDir (pas de Stat par fichier), avec option de suivi
+// des liens symboliques vers des dossiers. Les boucles sont détectées par identifiant
+// de fichier (périphérique + inode, ou volume + index sous Windows) sur les dossiers parents.
+
+// Identifiant unique d'un fichier sur la machine
+type fileID struct {
+	dev uint64
+	ino uint64
+}
+
+// Fonction appelée pour chaque dossier et fichier ; filepath.SkipDir sur un dossier évite de le parcourir
+// Pour un lien symbolique, d décrit la cible. d.Info() coûte un Lstat sous Unix : à n'appeler que si besoin
+type walkFunc func(path string, d fs.DirEntry) error
+
+// Parcours d'un dossier avec bilan des liens cassés et des boucles évitées
+type treeWalker struct {
+	FollowSymlinks bool
+	Broken         []string // liens dont la cible n'existe pas
+	Loops          []string // liens vers un dossier parent, non suivis
+}
+
+// Parcourt root et appelle fn pour chaque dossier et fichier, dans l'ordre lexical
+func (w *treeWalker) Walk(root string, fn walkFunc) error {
+	return w.walk(root, nil, fn)
+}
+
+// Parcours de dir ; ancestors contient les dossiers déjà ouverts au-dessus de lui via des liens suivis
+func (w *treeWalker) walk(dir string, ancestors []fileID, fn walkFunc) error {
+	ids := make(map[string]fileID) // identifiants des dossiers du parcours courant
+
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		// "lien/" (racine d'un lien suivi) est affiché "lien"
+		p = filepath.Clean(p)
+		if err != nil {
+			// Racine introuvable : erreur, dossier illisible : on l'ignore
+			if d == nil {
+				return err
+			}
+			return nil
+		}
+
+		// Lien symbolique : on regarde la cible
+		if d.Type()&fs.ModeSymlink != 0 {
+			target, err := os.Stat(p)
+			if err != nil {
+				w.Broken = append(w.Broken, p)
+				return nil
+			}
+			if !target.IsDir() {
+				return fn(p, fs.FileInfoToDirEntry(target))
+			}
+			if !w.FollowSymlinks {
+				return nil
+			}
+
+			// Boucle : la cible est le dossier courant ou l'un de ses parents
+			chain := append([]fileID(nil), ancestors...)
+			for q := filepath.Dir(p); ; q = filepath.Dir(q) {
+				if id, ok := ids[q]; ok {
+					chain = append(chain, id)
+				}
+				if q == filepath.Dir(q) {
+					break
+				}
+			}
+			if id, ok := getFileID(p, target); ok {
+				for _, a := range chain {
+					if a == id {
+						w.Loops = append(w.Loops, p)
+						return nil
+					}
+				}
+				chain = append(chain, id)
+			}
+
+			if err := fn(p, fs.FileInfoToDirEntry(target)); err != nil {
+				if err == filepath.SkipDir {
+					return nil
+				}
+				return err
+			}
+			// Le séparateur final fait suivre le lien par WalkDir
+			return w.walk(p+string(os.PathSeparator), chain, func(sp string, sd fs.DirEntry) error {
+				if sp == p {
+					return nil // déjà signalé ci-dessus
+				}
+				return fn(sp, sd)
+			})
+		}
+
+		// Identifiant des dossiers, seulement utile pour repérer les boucles quand les liens sont suivis
+		if d.IsDir() && w.FollowSymlinks {
+			if info, err := d.Info(); err == nil {
+				if id, ok := getFileID(p, info); ok {
+					ids[p] = id
+				}
+			}
+		}
+		return fn(p, d)
+	})
+}
+
+// Parcourt dir avec les filtres de la config et appelle fn pour chaque fichier gardé,
+// puis affiche le bilan des liens cassés et des boucles évitées
+func walkFiltered(cfg Config, dir string, filter *scanFilter, fn func(path string, info os.FileInfo)) error {
+	walker := &treeWalker{FollowSymlinks: cfg.FollowSymlinks}
+	err := walker.Walk(dir, func(path string, d fs.DirEntry) error {
+		if d.IsDir() {
+			if !filter.enterDir(path, d) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info, ok := filter.keepFile(path, d); ok {
+			fn(path, info)
+		}
+		return nil
+	})
+	for _, l := range walker.Broken {
+		fmt.Println("Lien cassé :", l)
+	}
+	for _, l := range walker.Loops {
+		fmt.Println("Lien ignoré (boucle) :", l)
+	}
+	return err
+}
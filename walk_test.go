@@ -0,0 +1,127 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Arborescence de test :
+//
+//	racine/a.txt, racine/sub/b.txt, autre/c.txt
+//	racine/sub/up -> ..        (boucle vers la racine)
+//	racine/sub/self -> .       (boucle vers le dossier lui-même)
+//	racine/lsub -> sub         (dossier frère : suivi, ses liens up et self restent des boucles)
+//	racine/link -> ../autre    (dossier hors de la racine : suivi)
+//	racine/flink -> a.txt      (fichier : toujours analysé)
+//	racine/broken -> absent    (lien cassé)
+func symlinkTree(t *testing.T) string {
+	t.Helper()
+	base := t.TempDir()
+	root := filepath.Join(base, "racine")
+	for _, d := range []string{filepath.Join(root, "sub"), filepath.Join(base, "autre")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeTestFile(t, root, "a.txt", []byte("a"))
+	writeTestFile(t, filepath.Join(root, "sub"), "b.txt", []byte("b"))
+	writeTestFile(t, filepath.Join(base, "autre"), "c.txt", []byte("c"))
+	links := []struct{ target, name string }{
+		{"..", "sub/up"},
+		{".", "sub/self"},
+		{"sub", "lsub"},
+		{filepath.Join("..", "autre"), "link"},
+		{"a.txt", "flink"},
+		{"absent", "broken"},
+	}
+	for _, l := range links {
+		if err := os.Symlink(l.target, filepath.Join(root, filepath.FromSlash(l.name))); err != nil {
+			t.Skipf("liens symboliques indisponibles : %v", err)
+		}
+	}
+	return root
+}
+
+// Chemins relatifs à root, triés et séparés par des espaces
+func relPaths(root string, paths []string) string {
+	var rel []string
+	for _, p := range paths {
+		r, _ := filepath.Rel(root, p)
+		rel = append(rel, filepath.ToSlash(r))
+	}
+	sort.Strings(rel)
+	return strings.Join(rel, " ")
+}
+
+func TestTreeWalkerSymlinks(t *testing.T) {
+	root := symlinkTree(t)
+	tests := []struct {
+		follow                     bool
+		dirs, files, loops, broken string
+	}{
+		{
+			follow: false,
+			dirs:   ". sub",
+			files:  "a.txt flink sub/b.txt",
+			broken: "broken",
+		},
+		{
+			follow: true,
+			dirs:   ". link lsub sub",
+			files:  "a.txt flink link/c.txt lsub/b.txt sub/b.txt",
+			loops:  "lsub/self lsub/up sub/self sub/up",
+			broken: "broken",
+		},
+	}
+	for _, tt := range tests {
+		w := &treeWalker{FollowSymlinks: tt.follow}
+		var dirs, files []string
+		err := w.Walk(root, func(p string, d fs.DirEntry) error {
+			if d.IsDir() {
+				dirs = append(dirs, p)
+			} else {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("follow=%v : %v", tt.follow, err)
+		}
+		for _, c := range []struct{ what, got, want string }{
+			{"dossiers", relPaths(root, dirs), tt.dirs},
+			{"fichiers", relPaths(root, files), tt.files},
+			{"boucles", relPaths(root, w.Loops), tt.loops},
+			{"liens cassés", relPaths(root, w.Broken), tt.broken},
+		} {
+			if c.got != c.want {
+				t.Errorf("follow=%v : %s %q, attendu %q", tt.follow, c.what, c.got, c.want)
+			}
+		}
+	}
+}
+
+// SkipDir sur un lien suivi n'arrête que ce lien
+func TestTreeWalkerSkipLink(t *testing.T) {
+	root := symlinkTree(t)
+	w := &treeWalker{FollowSymlinks: true}
+	var files []string
+	err := w.Walk(root, func(p string, d fs.DirEntry) error {
+		if d.IsDir() && filepath.Base(p) == "lsub" {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() {
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := relPaths(root, files), "a.txt flink link/c.txt sub/b.txt"; got != want {
+		t.Errorf("fichiers %q, attendu %q", got, want)
+	}
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+)
+
+// ------- Analyse d'une page web --------
+// Même traitement que pour Wikipédia, pour n'importe quelle adresse : le contenu est choisi par
+// le sélecteur CSS web_selector, ou à défaut par une heuristique à la manière des modes "lecture"
+// des navigateurs (balise <article>/<main>, sinon le bloc qui contient le plus de paragraphes
+// de texte et le moins de liens), puis mots, langue et filtrage par mot-clé.
+
+// Éléments qui ne font jamais partie du contenu principal
+const webNoiseSelector = "script, style, noscript, template, iframe, svg, form, nav, header, footer, aside, " +
+	"[role=navigation], [role=banner], [role=contentinfo], [role=complementary], [aria-hidden=true]"
+
+// Balises sémantiques essayées avant les scores, si elles sont seules dans la page
+var webSemanticSelectors = []string{"[itemprop=articleBody]", "article", "main", "[role=main]"}
+
+// Texte minimal (en caractères) d'une balise sémantique ou d'un paragraphe pour être pris en compte
+const (
+	webMinContentText   = 250
+	webMinParagraphText = 25
+)
+
+// Noms de classe ou d'id qui annoncent du contenu, ou au contraire des menus et des encarts
+var (
+	webPositiveRe = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|story|text`)
+	webNegativeRe = regexp.MustCompile(`(?i)ad-|banner|comment|cookie|footer|footnote|menu|meta|nav|popup|promo|related|share|sidebar|social|sponsor|widget`)
+)
+
+// Charset déclaré dans la page (<meta charset> ou http-equiv)
+var metaCharsetRe = regexp.MustCompile(`(?i)<meta[^>]+charset=["']?([\w-]+)`)
+
+// Décode le HTML en UTF-8 : charset de l'en-tête Content-Type, puis de la balise <meta>,
+// sinon détection comme pour les fichiers (UTF-8, Windows-1252, Latin-1...).
+// unsupported est le charset déclaré mais non pris en charge (Shift_JIS...), décodé par la détection
+func decodeHTML(body []byte, contentType string) (text, unsupported string) {
+	charset := ""
+	if _, params, err := mime.ParseMediaType(contentType); err == nil {
+		charset = params["charset"]
+	}
+	if charset == "" {
+		head := body[:min(len(body), 2048)]
+		if m := metaCharsetRe.FindSubmatch(head); m != nil {
+			charset = string(m[1])
+		}
+	}
+	enc := normalizeEncoding(charset)
+	if strings.EqualFold(charset, "us-ascii") || strings.EqualFold(charset, "ascii") {
+		enc = encUTF8 // sous-ensemble d'UTF-8
+	}
+	if enc == "" {
+		unsupported = charset
+	}
+	// Un UTF-8 annoncé mais invalide est redétecté
+	if enc != "" && enc != encUTF8 {
+		return decodeBytes(body, enc), ""
+	}
+	text, _ = decodeText(body)
+	return text, unsupported
+}
+
+// Bonus ou malus d'un élément d'après sa classe et son id
+func classWeight(s *goquery.Selection) float64 {
+	w := 0.0
+	for _, attr := range []string{"class", "id"} {
+		v := s.AttrOr(attr, "")
+		if v == "" {
+			continue
+		}
+		if webNegativeRe.MatchString(v) {
+			w -= 25
+		}
+		if webPositiveRe.MatchString(v) {
+			w += 25
+		}
+	}
+	return w
+}
+
+// Part du texte d'un élément qui se trouve dans des liens (0 à 1)
+func linkDensity(s *goquery.Selection) float64 {
+	total := len(blockText(s))
+	if total == 0 {
+		return 1
+	}
+	links := 0
+	s.Find("a").Each(func(i int, a *goquery.Selection) {
+		links += len(blockText(a))
+	})
+	return float64(links) / float64(total)
+}
+
+// Bloc candidat au contenu principal
+type webCandidate struct {
+	sel   *goquery.Selection
+	score float64
+}
+
+// Contenu principal de la page et la façon dont il a été trouvé
+func webMainContent(doc *goquery.Document) (*goquery.Selection, string) {
+	root := doc.Selection.Clone()
+	root.Find(webNoiseSelector).Remove()
+
+	// 1) Balise sémantique unique avec assez de texte
+	for _, sel := range webSemanticSelectors {
+		if m := root.Find(sel); m.Length() == 1 && len(blockText(m)) >= webMinContentText {
+			return m, sel
+		}
+	}
+
+	// 2) Chaque paragraphe donne des points à son parent, et moitié moins au grand-parent
+	scores := make(map[*html.Node]*webCandidate)
+	var order []*html.Node
+	add := func(s *goquery.Selection, points float64) {
+		if s.Length() == 0 {
+			return
+		}
+		n := s.Get(0)
+		c := scores[n]
+		if c == nil {
+			c = &webCandidate{sel: s, score: classWeight(s)}
+			switch goquery.NodeName(s) {
+			case "div", "article", "section":
+				c.score += 5
+			case "td", "blockquote", "pre":
+				c.score += 3
+			case "body", "html":
+				c.score -= 5
+			}
+			scores[n] = c
+			order = append(order, n)
+		}
+		c.score += points
+	}
+	root.Find("p, pre, td, blockquote").Each(func(i int, p *goquery.Selection) {
+		text := blockText(p)
+		if len(text) < webMinParagraphText {
+			return
+		}
+		// 1 point, plus un par virgule, plus un par tranche de 100 caractères (3 au plus)
+		points := 1 + float64(strings.Count(text, ",")) + float64(min(len(text)/100, 3))
+		parent := p.Parent()
+		add(parent, points)
+		add(parent.Parent(), points/2)
+	})
+
+	var best *webCandidate
+	for _, n := range order {
+		c := scores[n]
+		c.score *= 1 - linkDensity(c.sel)
+		if best == nil || c.score > best.score {
+			best = c
+		}
+	}
+	if best != nil {
+		desc := goquery.NodeName(best.sel)
+		if id := best.sel.AttrOr("id", ""); id != "" {
+			desc += "#" + id
+		} else if class := strings.Fields(best.sel.AttrOr("class", "")); len(class) > 0 {
+			desc += "." + class[0]
+		}
+		return best.sel, fmt.Sprintf("%s (score %.0f)", desc, best.score)
+	}
+
+	// 3) Aucun paragraphe : tout le corps de la page
+	if body := root.Find("body"); body.Length() > 0 {
+		return body.First(), "body"
+	}
+	return root, "page entière"
+}
+
+// Contenu désigné par un sélecteur CSS : les éléments trouvés sont regroupés dans l'ordre de la page
+func webSelectedContent(doc *goquery.Document, selector string) (*goquery.Selection, int) {
+	matches := doc.Find(selector)
+	box, _ := goquery.NewDocumentFromReader(strings.NewReader(`<div id="fileops-content"></div>`))
+	content := box.Find("#fileops-content")
+	content.AppendSelection(matches.Clone())
+	return content, matches.Length()
+}
+
+// Adresse saisie : http(s) uniquement, https:// ajouté si le schéma manque
+func normalizeWebURL(input string) (*url.URL, error) {
+	s := strings.TrimSpace(input)
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("seules les adresses http et https sont acceptées : %s", input)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("adresse sans nom d'hôte : %s", input)
+	}
+	return u, nil
+}
+
+// CHOIX G : analyse d'une page web quelconque
+func choixWeb(cfg Config, reader *bufio.Reader) {
+	fmt.Print("Adresse de la page : ")
+	input, _ := reader.ReadString('\n')
+	if strings.TrimSpace(input) == "" {
+		fmt.Println("Adresse vide, abandon.")
+		return
+	}
+	u, err := normalizeWebURL(input)
+	if err != nil {
+		fmt.Println("Erreur :", err)
+		return
+	}
+
+	fmt.Println("Téléchargement de :", u)
+	body, header, err := httpGet(cfg, u.String())
+	if err != nil {
+		fmt.Println("Erreur téléchargement :", err)
+		return
+	}
+	if ct := header.Get("Content-Type"); ct != "" && !strings.Contains(ct, "html") {
+		fmt.Println("Attention : la page n'est pas du HTML :", ct)
+	}
+	decoded, unsupported := decodeHTML(body, header.Get("Content-Type"))
+	if unsupported != "" {
+		fmt.Println("Attention : encodage", unsupported, "non pris en charge, détection automatique utilisée (le texte peut être illisible).")
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(decoded))
+	if err != nil {
+		fmt.Println("Erreur lecture HTML :", err)
+		return
+	}
+	if title := blockText(doc.Find("title").First()); title != "" {
+		fmt.Println("Titre :", title)
+	}
+
+	// Contenu : sélecteur de la config (ou saisi), sinon heuristique
+	selector := cfg.WebSelector
+	label := selector
+	if label == "" {
+		label = "détection automatique"
+	}
+	fmt.Printf("Sélecteur CSS du contenu (ENTER = %s) : ", label)
+	if s, _ := reader.ReadString('\n'); strings.TrimSpace(s) != "" {
+		selector = strings.TrimSpace(s)
+	}
+	// Avec un sélecteur invalide, goquery rend une sélection vide sans signaler d'erreur :
+	// il est vérifié avant pour afficher l'erreur de syntaxe plutôt que « 0 élément »
+	if selector != "" {
+		if _, err := cascadia.ParseGroup(selector); err != nil {
+			fmt.Println("Sélecteur CSS invalide :", err)
+			return
+		}
+	}
+	var content *goquery.Selection
+	if selector != "" {
+		var n int
+		content, n = webSelectedContent(doc, selector)
+		fmt.Printf("Sélecteur %s : %d élément(s)\n", selector, n)
+		if n == 0 {
+			return
+		}
+	} else {
+		var how string
+		content, how = webMainContent(doc)
+		fmt.Println("Contenu détecté :", how)
+	}
+
+	// Paragraphes, titres et listes du contenu ; à défaut, tout son texte
+	opts := wikiExtractOptions{Headings: true, Lists: true}
+	blocks := extractBlocks(content, opts)
+	if len(blocks) == 0 {
+		if text := blockText(content); text != "" {
+			blocks = []wikiBlock{{Kind: "p", Text: text}}
+		}
+	}
+
+	var lines []string
+	paragraphs := 0
+	totalWords, totalLen := 0, 0
+	for _, b := range blocks {
+		if b.Kind == "p" {
+			paragraphs++
+		}
+		if b.Kind != "h" {
+			lines = append(lines, b.Text)
+			w, l := countWords(b.Text)
+			totalWords += w
+			totalLen += l
+		}
+	}
+	fmt.Println("Paragraphes extraits :", paragraphs)
+	if len(blocks) > paragraphs {
+		fmt.Println("Titres et éléments de liste :", len(blocks)-paragraphs)
+	}
+	if totalWords > 0 {
+		fmt.Println("Nombre de mots :", totalWords)
+		fmt.Println("Longueur moyenne :", totalLen/totalWords)
+	}
+	lang := detectLanguage(strings.Join(lines, "\n"))
+	fmt.Println("Langue détectée :", lang)
+
+	// FILTRAGE PAR MOT-CLÉ
+	fmt.Print("Mot-clé pour filtrer (ENTER = aucun) : ")
+	keyword, _ := reader.ReadString('\n')
+	keyword = strings.TrimSpace(keyword)
+
+	var out strings.Builder
+	count := 0
+	for _, b := range blocks {
+		if keyword == "" || strings.Contains(b.Text, keyword) {
+			out.WriteString(b.String() + "\n")
+			if keyword != "" {
+				count++
+			}
+		}
+	}
+
+	os.MkdirAll(cfg.OutDir, os.ModePerm)
+	// La requête fait partie du nom (empreinte courte) : ?page=1 et ?page=2 ne s'écrasent pas
+	name := "web_" + safeFileName(u.Host+strings.TrimSuffix(u.Path, "/"))
+	if u.RawQuery != "" {
+		name += "_" + sha256Hex([]byte(u.RawQuery))[:8]
+	}
+	outFile := filepath.Join(cfg.OutDir, name+".txt")
+	if err := writeTextFile(outFile, out.String(), cfg.OutputEncoding); err != nil {
+		fmt.Println("Erreur écriture :", err)
+		return
+	}
+	fmt.Println("Fichier généré :", outFile)
+	if keyword != "" {
+		fmt.Println("Lignes contenant le mot-clé :", count)
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestDecodeHTML(t *testing.T) {
+	latin := []byte("<p>caf\xe9 cr\xe8me br\xfbl\xe9e</p>")
+	utf8 := []byte("<p>café crème brûlée</p>")
+	meta := func(decl string, body []byte) []byte {
+		return append([]byte("<html><head>"+decl+"</head><body>"), body...)
+	}
+	tests := []struct {
+		name, contentType string
+		body              []byte
+		unsupported       string
+	}{
+		{"en-tête windows-1252", "text/html; charset=windows-1252", latin, ""},
+		{"en-tête ISO-8859-1", `text/html; charset="ISO-8859-1"`, latin, ""},
+		{"meta charset", "text/html", meta(`<meta charset="iso-8859-1">`, latin), ""},
+		{"meta http-equiv", "", meta(`<meta http-equiv="Content-Type" content="text/html; charset=windows-1252">`, latin), ""},
+		{"en-tête prioritaire sur meta", "text/html; charset=utf-8", meta(`<meta charset="iso-8859-1">`, utf8), ""},
+		{"UTF-8 annoncé mais invalide", "text/html; charset=utf-8", latin, ""},
+		{"sans charset", "", utf8, ""},
+		{"us-ascii", "text/html; charset=us-ascii", utf8, ""},
+		{"charset non pris en charge", "text/html; charset=Shift_JIS", utf8, "Shift_JIS"},
+		{"meta non prise en charge", "", meta(`<meta charset="gb2312">`, latin), "gb2312"},
+	}
+	for _, tt := range tests {
+		text, unsupported := decodeHTML(tt.body, tt.contentType)
+		if !strings.Contains(text, "café crème brûlée") {
+			t.Errorf("%s : %q", tt.name, text)
+		}
+		if unsupported != tt.unsupported {
+			t.Errorf("%s : non pris en charge %q, attendu %q", tt.name, unsupported, tt.unsupported)
+		}
+	}
+}
+
+// Paragraphe d'au moins webMinParagraphText caractères, avec des virgules
+func webParagraph(i int) string {
+	return "<p>Paragraphe " + string(rune('A'+i)) + " du contenu, avec assez de texte, quelques virgules, et des phrases complètes pour compter.</p>"
+}
+
+func webDoc(t *testing.T, body string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + body + "</body></html>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestWebMainContent(t *testing.T) {
+	var paragraphs string
+	for i := range 4 {
+		paragraphs += webParagraph(i)
+	}
+	// Bloc de liens longs : beaucoup de texte, mais presque tout dans des liens
+	var links string
+	for i := range 6 {
+		links += `<p><a href="/x">Article recommandé numéro ` + string(rune('1'+i)) + `, à lire absolument, vraiment très intéressant</a></p>`
+	}
+	nav := `<nav><p>Accueil, Rubriques, Contact, Plan du site et mentions légales du site</p></nav>`
+	tests := []struct {
+		name, body, how, contains string
+	}{
+		{
+			"balise article",
+			nav + `<article>` + paragraphs + `</article><div>` + links + `</div>`,
+			"article", "Paragraphe A",
+		},
+		{
+			"itemprop prioritaire sur article",
+			`<div itemprop="articleBody">` + paragraphs + `</div><article>` + paragraphs + `</article>`,
+			"[itemprop=articleBody]", "Paragraphe D",
+		},
+		{
+			"deux balises article : le bloc qui les contient",
+			`<div id="liens">` + links + `</div><div id="texte"><article>` + paragraphs + `</article><article>` + paragraphs + `</article></div>`,
+			"div#texte (score", "Paragraphe B",
+		},
+		{
+			"article trop court : scores",
+			`<article><p>Court.</p></article><div id="contenu">` + paragraphs + `</div>`,
+			"div#contenu (score", "Paragraphe C",
+		},
+		{
+			"densité de liens",
+			`<div class="bloc">` + links + links + `</div><div class="bloc2">` + paragraphs + `</div>`,
+			"div.bloc2 (score", "Paragraphe A",
+		},
+		{
+			"sans paragraphe",
+			`<span>Texte seul</span>`,
+			"body", "Texte seul",
+		},
+	}
+	for _, tt := range tests {
+		content, how := webMainContent(webDoc(t, tt.body))
+		if !strings.HasPrefix(how, tt.how) {
+			t.Errorf("%s : contenu %q, attendu %q", tt.name, how, tt.how)
+		}
+		text := blockText(content)
+		if !strings.Contains(text, tt.contains) {
+			t.Errorf("%s : %q absent du contenu", tt.name, tt.contains)
+		}
+		if strings.Contains(text, "Article recommandé") || strings.Contains(text, "Accueil") {
+			t.Errorf("%s : liens ou menu dans le contenu", tt.name)
+		}
+	}
+}
+
+func TestWebSelectedContent(t *testing.T) {
+	doc := webDoc(t, `<div class="post"><p>Un</p></div><aside><p>Pub</p></aside><div class="post"><p>Deux</p></div>`)
+	content, n := webSelectedContent(doc, "div.post")
+	blocks := extractBlocks(content, wikiExtractOptions{})
+	if n != 2 || len(blocks) != 2 || blocks[0].Text != "Un" || blocks[1].Text != "Deux" {
+		t.Errorf("%d élément(s), blocs %+v", n, blocks)
+	}
+	if _, n := webSelectedContent(doc, "article"); n != 0 {
+		t.Errorf("%d élément(s), attendu 0", n)
+	}
+}
+
+func TestNormalizeWebURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"example.org/page", "https://example.org/page"},
+		{"  http://example.org  ", "http://example.org"},
+		{"https://example.org/a?b=c#d", "https://example.org/a?b=c#d"},
+		{"ftp://example.org", ""},
+		{"file:///etc/passwd", ""},
+		{"https:///sans-hote", ""},
+	}
+	for _, tt := range tests {
+		u, err := normalizeWebURL(tt.in)
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("normalizeWebURL(%q) = %s, erreur attendue", tt.in, u)
+		case tt.want != "" && (err != nil || u.String() != tt.want):
+			t.Errorf("normalizeWebURL(%q) = %v, %v ; attendu %s", tt.in, u, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ------- Wikipédia : édition et adresse --------
+// L'édition (fr, en, de...) et l'adresse des articles viennent de la config ou des flags,
+// ce qui permet d'analyser une autre langue ou de viser un miroir local / un serveur de test.
+
+// Édition et adresse par défaut
+const (
+	defaultWikiLang    = "fr"
+	defaultWikiBaseURL = "https://{lang}.wikipedia.org/wiki/"
+)
+
+// User-Agent identifiant le programme, comme le demande la politique de Wikimedia :
+// nom/version (contact) bibliothèque
+const defaultUserAgent = "fileops/1.0 (https://github.com/Adrien137/EvalGolang) Go-http-client"
+
+// Code d'édition : lettres minuscules et tirets (fr, en, simple, zh-yue...)
+var wikiLangRe = regexp.MustCompile(`^[a-z]{2,12}(-[a-z]{2,12})*$`)
+
+// Vérifie l'édition et l'adresse de la config, valeurs par défaut si absentes ou invalides
+func checkWikiConfig(lang, base string) (string, string) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		lang = defaultWikiLang
+	} else if !wikiLangRe.MatchString(lang) {
+		fmt.Println("Édition Wikipédia invalide :", lang, "-", defaultWikiLang, "utilisé.")
+		lang = defaultWikiLang
+	}
+
+	base = strings.TrimSpace(base)
+	if base == "" {
+		base = defaultWikiBaseURL
+	}
+	u, err := url.Parse(strings.ReplaceAll(base, "{lang}", lang))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fmt.Println("Adresse Wikipédia invalide :", base, "-", defaultWikiBaseURL, "utilisée.")
+		base = defaultWikiBaseURL
+	}
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return lang, base
+}
+
+// Adresse de base des articles pour l'édition choisie
+func wikiBase(cfg Config) string {
+	return strings.ReplaceAll(cfg.WikiBaseURL, "{lang}", cfg.WikiLang)
+}
+
+// URL d'un article (titre normalisé, encodé pour l'URL)
+func wikiArticleURL(cfg Config, title string) string {
+	return wikiBase(cfg) + escapeWikiTitle(title)
+}
+
+// ------- Titres d'articles --------
+
+// Normalise un titre à la manière de Wikipédia : espaces -> "_", espaces multiples réduits,
+// première lettre en majuscule. Accepte aussi une URL d'article ou un titre déjà encodé (%C3%A9).
+func normalizeWikiTitle(input string) (string, error) {
+	t := strings.TrimSpace(input)
+
+	// URL complète : https://fr.wikipedia.org/wiki/Titre
+	if u, err := url.Parse(t); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		i := strings.Index(u.Path, "/wiki/")
+		if i < 0 {
+			return "", fmt.Errorf("URL sans /wiki/ : %s", input)
+		}
+		t = u.Path[i+len("/wiki/"):]
+	} else if dec, err := url.PathUnescape(t); err == nil {
+		t = dec
+	}
+
+	// L'ancre (#section) ne fait pas partie du titre
+	if i := strings.Index(t, "#"); i >= 0 {
+		t = t[:i]
+	}
+
+	t = strings.Join(strings.FieldsFunc(t, func(r rune) bool {
+		return r == '_' || unicode.IsSpace(r)
+	}), "_")
+	if t == "" {
+		return "", fmt.Errorf("titre vide")
+	}
+	if strings.ContainsAny(t, "<>[]{}|") {
+		return "", fmt.Errorf("caractère interdit dans un titre : %s", t)
+	}
+	for _, seg := range strings.Split(t, "/") {
+		if seg == "." || seg == ".." {
+			return "", fmt.Errorf("titre invalide : %s", t)
+		}
+	}
+
+	r, size := utf8.DecodeRuneInString(t)
+	return string(unicode.ToUpper(r)) + t[size:], nil
+}
+
+// Titre lisible : "_" -> espace
+func displayWikiTitle(title string) string {
+	return strings.ReplaceAll(title, "_", " ")
+}
+
+// Encode le titre pour le chemin de l'URL, les "/" des sous-pages restent tels quels
+func escapeWikiTitle(title string) string {
+	segs := strings.Split(title, "/")
+	for i, s := range segs {
+		segs[i] = url.PathEscape(s)
+	}
+	return strings.Join(segs, "/")
+}
+
+// Noms réservés par Windows, quelle que soit l'extension
+var reservedFileNames = regexp.MustCompile(`(?i)^(con|prn|aux|nul|com[0-9]|lpt[0-9])(\.|$)`)
+
+// Longueur maximale d'un nom de fichier généré (en octets, sans le préfixe ni l'extension)
+const maxFileNameLen = 150
+
+// Nom de fichier sûr : pas de séparateur de dossier, de caractère interdit sous Windows,
+// de nom réservé, ni de ".." qui sortirait de OutDir
+func safeFileName(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if r < 32 || strings.ContainsRune(`<>:"/\|?*`, r) {
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	s := strings.Trim(sb.String(), ". ")
+	if len(s) > maxFileNameLen {
+		cut := maxFileNameLen
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut]
+	}
+	if s == "" {
+		return "_"
+	}
+	if reservedFileNames.MatchString(s) {
+		s = "_" + s
+	}
+	return s
+}
+
+// ------- Téléchargement : API MediaWiki, page HTML en secours --------
+// L'API (action=parse) donne le contenu de l'article sans l'habillage du site, avec le titre
+// canonique (après redirection) et le numéro de révision ; la date de la révision vient d'une
+// seconde requête (action=query). Si l'API ne répond pas, la page HTML est lue comme avant.
+
+// Article absent : inutile d'essayer la page HTML
+var errWikiMissing = errors.New("article introuvable")
+
+// Page téléchargée
+type wikiPage struct {
+	Title      string    // titre canonique, avec des "_"
+	URL        string    // adresse de l'article
+	RevID      int64     // numéro de révision (0 si inconnu)
+	Modified   time.Time // date de la révision (zéro si inconnue)
+	Source     string    // "api" ou "html"
+	Categories []string  // catégories visibles (sans les catégories cachées de maintenance)
+	Doc        *goquery.Document
+}
+
+// Adresse de l'API : wiki_api_url, ou déduite de l'adresse des articles (/wiki/ -> /w/api.php)
+func wikiAPIURL(cfg Config) string {
+	if cfg.WikiAPIURL != "" {
+		return strings.ReplaceAll(cfg.WikiAPIURL, "{lang}", cfg.WikiLang)
+	}
+	base := wikiBase(cfg)
+	if i := strings.LastIndex(base, "/wiki/"); i >= 0 {
+		return base[:i] + "/w/api.php"
+	}
+	return strings.TrimSuffix(base, "/") + "/w/api.php"
+}
+
+// Télécharge un article par l'API, ou par la page HTML si l'API échoue (ou si wiki_fetch = html)
+func fetchWikiPage(cfg Config, title string) (*wikiPage, error) {
+	if cfg.WikiFetch != "html" {
+		page, err := fetchWikiAPI(cfg, title)
+		if err == nil || errors.Is(err, errWikiMissing) {
+			return page, err
+		}
+		fmt.Printf("API indisponible (%v), lecture de la page HTML.\n", err)
+	}
+	return fetchWikiHTML(cfg, title)
+}
+
+// Réponse de action=parse (formatversion=2)
+type apiParse struct {
+	Parse struct {
+		Title      string `json:"title"`
+		RevID      int64  `json:"revid"`
+		Text       string `json:"text"`
+		Categories []struct {
+			Category string `json:"category"`
+			Hidden   bool   `json:"hidden"`
+		} `json:"categories"`
+	} `json:"parse"`
+	Error *struct {
+		Code string `json:"code"`
+		Info string `json:"info"`
+	} `json:"error"`
+}
+
+// Réponse de action=query&prop=revisions (formatversion=2)
+type apiRevisions struct {
+	Query struct {
+		Pages []struct {
+			Revisions []struct {
+				Timestamp time.Time `json:"timestamp"`
+			} `json:"revisions"`
+		} `json:"pages"`
+	} `json:"query"`
+}
+
+// Téléchargement par l'API MediaWiki
+func fetchWikiAPI(cfg Config, title string) (*wikiPage, error) {
+	api := wikiAPIURL(cfg)
+	q := url.Values{
+		"action":        {"parse"},
+		"format":        {"json"},
+		"formatversion": {"2"},
+		"redirects":     {"1"},
+		"prop":          {"text|revid|categories"},
+		"page":          {title},
+	}
+	body, _, err := httpGet(cfg, api+"?"+q.Encode())
+	if err != nil {
+		if errors.Is(err, errNotFound) {
+			err = fmt.Errorf("API introuvable : %s", api)
+		}
+		return nil, err
+	}
+	var r apiParse
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("réponse de l'API illisible : %v", err)
+	}
+	if r.Error != nil {
+		if r.Error.Code == "missingtitle" || r.Error.Code == "invalidtitle" {
+			return nil, errWikiMissing
+		}
+		return nil, fmt.Errorf("API : %s", r.Error.Info)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(r.Parse.Text))
+	if err != nil {
+		return nil, err
+	}
+	page := &wikiPage{
+		Title:  strings.ReplaceAll(r.Parse.Title, " ", "_"),
+		RevID:  r.Parse.RevID,
+		Source: "api",
+		Doc:    doc,
+	}
+	page.URL = wikiArticleURL(cfg, page.Title)
+	for _, c := range r.Parse.Categories {
+		if !c.Hidden {
+			page.Categories = append(page.Categories, displayWikiTitle(c.Category))
+		}
+	}
+
+	// Date de la révision (facultative)
+	q = url.Values{
+		"action":        {"query"},
+		"format":        {"json"},
+		"formatversion": {"2"},
+		"prop":          {"revisions"},
+		"rvprop":        {"timestamp"},
+		"revids":        {strconv.FormatInt(page.RevID, 10)},
+	}
+	if body, _, err := httpGet(cfg, api+"?"+q.Encode()); err == nil {
+		var rv apiRevisions
+		if json.Unmarshal(body, &rv) == nil && len(rv.Query.Pages) > 0 && len(rv.Query.Pages[0].Revisions) > 0 {
+			page.Modified = rv.Query.Pages[0].Revisions[0].Timestamp
+		}
+	}
+	return page, nil
+}
+
+// Variables de la page HTML (mw.config) : titre canonique et révision
+var (
+	wgPageNameRe   = regexp.MustCompile(`"wgPageName":"((?:[^"\\]|\\.)*)"`)
+	wgRevisionIDRe = regexp.MustCompile(`"wgRevisionId":(\d+)`)
+)
+
+// Téléchargement de la page HTML (méthode historique, en secours)
+func fetchWikiHTML(cfg Config, title string) (*wikiPage, error) {
+	u := wikiArticleURL(cfg, title)
+	body, header, err := httpGet(cfg, u)
+	if errors.Is(err, errNotFound) {
+		return nil, errWikiMissing
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+	if err != nil {
+		return nil, err
+	}
+	page := &wikiPage{Title: title, URL: u, Source: "html", Doc: doc}
+
+	if m := wgPageNameRe.FindSubmatch(body); m != nil {
+		var name string
+		if json.Unmarshal([]byte(`"`+string(m[1])+`"`), &name) == nil && name != "" {
+			page.Title = name
+			page.URL = wikiArticleURL(cfg, name)
+		}
+	}
+	if m := wgRevisionIDRe.FindSubmatch(body); m != nil {
+		page.RevID, _ = strconv.ParseInt(string(m[1]), 10, 64)
+	}
+	if t, err := http.ParseTime(header.Get("Last-Modified")); err == nil {
+		page.Modified = t
+	}
+
+	// Catégories en bas de page (les catégories cachées sont dans #mw-hidden-catlinks)
+	cats := doc.Find("#mw-normal-catlinks li a")
+	if cats.Length() == 0 {
+		cats = doc.Find("#catlinks li a").Not("#mw-hidden-catlinks a")
+	}
+	cats.Each(func(i int, a *goquery.Selection) {
+		if name := blockText(a); name != "" {
+			page.Categories = append(page.Categories, name)
+		}
+	})
+	return page, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestNormalizeWikiTitle(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"tour eiffel", "Tour_eiffel"},
+		{"  Tour   Eiffel  ", "Tour_Eiffel"},
+		{"Tour__Eiffel", "Tour_Eiffel"},
+		{"https://fr.wikipedia.org/wiki/Tour_Eiffel", "Tour_Eiffel"},
+		{"https://fr.wikipedia.org/wiki/%C3%89cole_normale", "École_normale"},
+		{"%C3%A9t%C3%A9", "Été"},
+		{"Paris#Histoire", "Paris"},
+		{"C++", "C++"},
+		{"AC/DC", "AC/DC"}, // sous-page
+		{"100%", "100%"},   // encodage invalide : gardé tel quel
+		{"%252E%252E", "%2E%2E"},
+	}
+	for _, tt := range tests {
+		got, err := normalizeWikiTitle(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("normalizeWikiTitle(%q) = %q, %v ; attendu %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeWikiTitleRejected(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"   ",
+		"#Histoire",
+		"a[b]",
+		"a|b",
+		"https://fr.wikipedia.org/w/index.php?title=Paris",
+		"..",
+		"./Paris",
+		"../etc/passwd",
+		"Paris/../../secret",
+		"%2E%2E/etc",
+		"%2e%2e%2fsecret",
+		"https://fr.wikipedia.org/wiki/..%2F..%2Fsecret",
+	} {
+		if got, err := normalizeWikiTitle(in); err == nil {
+			t.Errorf("normalizeWikiTitle(%q) = %q, erreur attendue", in, got)
+		}
+	}
+}
+
+func TestSafeFileName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Tour_Eiffel", "Tour_Eiffel"},
+		{"AC/DC", "AC_DC"},
+		{`a\b:c*?"<>|`, "a_b_c______"},
+		{"../../etc", "_.._etc"},
+		{"..", "_"},
+		{"", "_"},
+		{" .cache. ", "cache"},
+		{"\x00a\tb", "_a_b"},
+		{"CON", "_CON"},
+		{"com1.txt", "_com1.txt"},
+		{"Console", "Console"},
+	}
+	for _, tt := range tests {
+		if got := safeFileName(tt.in); got != tt.want {
+			t.Errorf("safeFileName(%q) = %q, attendu %q", tt.in, got, tt.want)
+		}
+	}
+
+	// Nom trop long : coupé sans couper un caractère
+	for _, in := range []string{strings.Repeat("é", 100), "a" + strings.Repeat("é", 100)} {
+		got := safeFileName(in)
+		if len(got) > maxFileNameLen || len(got) < maxFileNameLen-1 || !utf8.ValidString(got) {
+			t.Errorf("safeFileName(%d octets) : %d octets, UTF-8 valide %v", len(in), len(got), utf8.ValidString(got))
+		}
+	}
+}
+
+func TestEscapeWikiTitle(t *testing.T) {
+	tests := map[string]string{
+		"Tour_Eiffel": "Tour_Eiffel",
+		"Été":         "%C3%89t%C3%A9",
+		"AC/DC":       "AC/DC",
+		"C++ ?":       "C++%20%3F",
+		"100%":        "100%25",
+	}
+	for in, want := range tests {
+		if got := escapeWikiTitle(in); got != want {
+			t.Errorf("escapeWikiTitle(%q) = %q, attendu %q", in, got, want)
+		}
+	}
+}
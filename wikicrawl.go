@@ -0,0 +1,339 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ------- Wikipédia : lot d'articles et exploration des liens --------
+// Un lot est une liste de titres lue dans un fichier ; l'exploration part d'un article et suit
+// ses liens internes niveau par niveau, jusqu'à une profondeur et un nombre de pages maximum.
+// Les pages sont téléchargées par quelques workers, avec une pause (wiki_crawl_delay_ms) après chaque
+// page ; le débit par hôte (http_rate) est limité par le client HTTP, pour chaque requête.
+// Chaque page est enregistrée (texte, Markdown, JSON) et un index résume l'ensemble.
+
+// Réglages d'une exploration
+type crawlOptions struct {
+	Depth       int           // profondeur maximale (0 = seulement les articles de départ)
+	MaxPages    int           // nombre maximal de pages téléchargées
+	Concurrency int           // téléchargements simultanés
+	Delay       time.Duration // pause d'un worker après chaque page
+}
+
+func crawlOptionsFrom(cfg Config) crawlOptions {
+	return crawlOptions{
+		Depth:       cfg.WikiCrawlDepth,
+		MaxPages:    cfg.WikiCrawlMaxPages,
+		Concurrency: cfg.WikiCrawlConcurrency,
+		Delay:       time.Duration(cfg.WikiCrawlDelayMs) * time.Millisecond,
+	}
+}
+
+// Page à télécharger
+type crawlItem struct {
+	Title  string
+	Depth  int
+	Parent string // page où le lien a été trouvé ("" pour les articles de départ)
+}
+
+// Résultat d'une page
+type crawlResult struct {
+	crawlItem
+	Status     string // ok, duplicate (même article qu'une page déjà enregistrée), missing ou error
+	Page       *wikiPage
+	Data       wikiData
+	Paragraphs int
+	Words      int
+	Lang       langResult
+	File       string
+	Err        error
+}
+
+// Nom de fichier (sans extension) d'une page, unique dans le dossier : deux titres peuvent donner
+// le même nom une fois les caractères interdits remplacés, ou ne différer que par la casse (même
+// fichier sous Windows et macOS). Le second reçoit alors un suffixe tiré de l'empreinte du titre.
+func crawlFileName(used map[string]bool, title string) string {
+	name := safeFileName(title)
+	if used[strings.ToLower(name)] {
+		name += "_" + sha256Hex([]byte(title))[:8]
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// Enregistre une page dans dir : <name>.txt, <name>.md et <name>.json
+func saveCrawledPage(cfg Config, dir, name string, r *crawlResult) error {
+	opts := wikiOptions(cfg)
+	blocks := extractWikiBlocks(r.Page.Doc, opts)
+
+	var txt strings.Builder
+	var text []string
+	for _, b := range blocks {
+		if !b.shown(opts) {
+			continue
+		}
+		txt.WriteString(b.String() + "\n")
+		if b.Kind == "p" {
+			r.Paragraphs++
+		}
+		if b.Kind != "h" {
+			w, _ := countWords(b.Text)
+			r.Words += w
+			text = append(text, b.Text)
+		}
+	}
+	r.Lang = detectLanguage(strings.Join(text, "\n"))
+
+	base := filepath.Join(dir, name)
+	r.File = base + ".txt"
+	if err := writeTextFile(r.File, txt.String(), cfg.OutputEncoding); err != nil {
+		return err
+	}
+	md := wikiMarkdown(displayWikiTitle(r.Page.Title), r.Page.URL, blocks)
+	if err := writeTextFile(base+".md", md, cfg.OutputEncoding); err != nil {
+		return err
+	}
+	return writeWikiData(base+".json", r.Data)
+}
+
+// Télécharge et enregistre les pages, niveau par niveau, à partir des titres de départ
+func crawlWiki(cfg Config, start []string, opts crawlOptions, dir string) []crawlResult {
+	workers := max(opts.Concurrency, 1)
+
+	// Titres déjà vus (demandés ou enregistrés), pour ne jamais télécharger deux fois le même article
+	queued := make(map[string]bool)
+	var frontier []crawlItem
+	for _, t := range start {
+		if !queued[t] {
+			queued[t] = true
+			frontier = append(frontier, crawlItem{Title: t})
+		}
+	}
+
+	var results []crawlResult
+	var mu sync.Mutex
+	saved := make(map[string]bool) // titres canoniques enregistrés (après redirection)
+	names := make(map[string]bool) // noms de fichiers pris, en minuscules
+	done := 0
+	for depth := 0; len(frontier) > 0 && len(results) < opts.MaxPages; depth++ {
+		if rem := opts.MaxPages - len(results); len(frontier) > rem {
+			frontier = frontier[:rem]
+		}
+
+		level := make([]crawlResult, len(frontier))
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for w := 0; w < min(workers, len(frontier)); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					r := &level[i]
+					r.crawlItem = frontier[i]
+					r.Page, r.Err = fetchWikiPage(cfg, r.Title)
+
+					mu.Lock()
+					dup := r.Err == nil && saved[r.Page.Title]
+					var name string
+					if r.Err == nil && !dup {
+						saved[r.Page.Title] = true
+						name = crawlFileName(names, r.Page.Title)
+					}
+					mu.Unlock()
+
+					switch {
+					case errors.Is(r.Err, errWikiMissing):
+						r.Status = "missing"
+					case r.Err != nil:
+						r.Status = "error"
+					case dup:
+						r.Status = "duplicate"
+					default:
+						r.Data = extractWikiData(cfg, r.Page)
+						if r.Err = saveCrawledPage(cfg, dir, name, r); r.Err != nil {
+							r.Status = "error"
+						} else {
+							r.Status = "ok"
+						}
+					}
+
+					mu.Lock()
+					done++
+					fmt.Printf("[%d] %s (profondeur %d) : %s\n", done, displayWikiTitle(r.Title), r.Depth, r.Status)
+					mu.Unlock()
+					time.Sleep(opts.Delay)
+				}
+			}()
+		}
+		for i := range frontier {
+			jobs <- i
+		}
+		close(jobs)
+		wg.Wait()
+		results = append(results, level...)
+
+		if depth >= opts.Depth {
+			break
+		}
+
+		// Niveau suivant : liens internes des pages enregistrées, dans l'ordre de la page
+		var next []crawlItem
+		for _, r := range level {
+			if r.Status != "ok" {
+				continue
+			}
+			queued[r.Page.Title] = true
+			for _, l := range r.Data.InternalLinks {
+				if !queued[l.Title] && !saved[l.Title] {
+					queued[l.Title] = true
+					next = append(next, crawlItem{Title: l.Title, Depth: depth + 1, Parent: r.Page.Title})
+				}
+			}
+		}
+		frontier = next
+	}
+	return results
+}
+
+// Table "pages" de l'index
+func crawlIndexTable(results []crawlResult) reportTable {
+	t := reportTable{Name: "pages", Columns: []string{"depth", "title", "status", "parent", "url", "revid",
+		"paragraphs", "words", "lang", "internal_links", "file", "error"}}
+	for _, r := range results {
+		row := []any{r.Depth, displayWikiTitle(r.Title), r.Status, displayWikiTitle(r.Parent), nil, nil, nil, nil, nil, nil, nil, nil}
+		if r.Page != nil {
+			row[1] = displayWikiTitle(r.Page.Title)
+			row[4] = r.Page.URL
+			row[5] = r.Page.RevID
+		}
+		if r.Status == "ok" {
+			row[6], row[7], row[8], row[9], row[10] = r.Paragraphs, r.Words, r.Lang.Lang, len(r.Data.InternalLinks), r.File
+		}
+		if r.Err != nil {
+			row[11] = r.Err.Error()
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// Lance l'exploration dans OutDir/<name> et écrit l'index
+func runWikiCrawl(cfg Config, start []string, opts crawlOptions, name string) {
+	dir := filepath.Join(cfg.OutDir, name)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		fmt.Println("Erreur création dossier :", err)
+		return
+	}
+	rate := "sans limite"
+	if cfg.HTTPRate > 0 {
+		rate = fmt.Sprintf("%g requête(s)/s", cfg.HTTPRate)
+	}
+	fmt.Printf("Pages max : %d, profondeur : %d, téléchargements simultanés : %d, débit par hôte : %s, pause : %v\n",
+		opts.MaxPages, opts.Depth, max(opts.Concurrency, 1), rate, opts.Delay)
+
+	started := time.Now()
+	results := crawlWiki(cfg, start, opts, dir)
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	fmt.Printf("%d page(s) en %v : %d enregistrée(s), %d doublon(s), %d introuvable(s), %d erreur(s)\n",
+		len(results), time.Since(started).Round(time.Millisecond), counts["ok"], counts["duplicate"], counts["missing"], counts["error"])
+
+	// L'index est écrit dans le dossier de l'exploration
+	sub := cfg
+	sub.OutDir = dir
+	written, err := writeReport(sub, "index", []reportTable{crawlIndexTable(results)})
+	if err != nil {
+		fmt.Println("Erreur rapport :", err)
+	}
+	for _, f := range written {
+		fmt.Println("Index généré :", f)
+	}
+}
+
+// Lit un fichier de titres : un titre ou une URL par ligne, lignes vides et commentaires (#) ignorés
+func readWikiTitles(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var titles []string
+	sc := bufio.NewScanner(f)
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		t, err := normalizeWikiTitle(line)
+		if err != nil {
+			fmt.Printf("Ligne %d ignorée : %v\n", n, err)
+			continue
+		}
+		titles = append(titles, t)
+	}
+	return titles, sc.Err()
+}
+
+// Choix C : lot d'articles lus dans un fichier
+func choixWikiBatch(cfg Config, reader *bufio.Reader) {
+	fmt.Print("Fichier de titres (un article par ligne) : ")
+	path, _ := reader.ReadString('\n')
+	path = strings.TrimSpace(path)
+	if path == "" {
+		fmt.Println("Fichier manquant.")
+		return
+	}
+	titles, err := readWikiTitles(path)
+	if err != nil {
+		fmt.Println("Erreur lecture :", err)
+		return
+	}
+	if len(titles) == 0 {
+		fmt.Println("Aucun titre dans", path)
+		return
+	}
+	fmt.Println("Articles :", len(titles))
+
+	// Un lot ne suit pas les liens
+	opts := crawlOptionsFrom(cfg)
+	opts.Depth = 0
+	opts.MaxPages = len(titles)
+	runWikiCrawl(cfg, titles, opts, "wiki_batch")
+}
+
+// Choix C : exploration des liens à partir d'un article
+func choixWikiCrawl(cfg Config, reader *bufio.Reader) {
+	fmt.Print("Article de départ : ")
+	s, _ := reader.ReadString('\n')
+	title, err := normalizeWikiTitle(s)
+	if err != nil {
+		fmt.Println("Erreur :", err)
+		return
+	}
+
+	opts := crawlOptionsFrom(cfg)
+	fmt.Printf("Profondeur (ENTER = %d) : ", opts.Depth)
+	s, _ = reader.ReadString('\n')
+	if opts.Depth, err = parseCount(s, opts.Depth); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Nombre maximal de pages (ENTER = %d) : ", opts.MaxPages)
+	s, _ = reader.ReadString('\n')
+	if opts.MaxPages, err = parseCount(s, opts.MaxPages); err != nil {
+		fmt.Println(err)
+		return
+	}
+	runWikiCrawl(cfg, []string{title}, opts, "wiki_crawl")
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Titres qui donnent le même nom de fichier : le premier garde le nom, les suivants ont un suffixe
+func TestCrawlFileName(t *testing.T) {
+	used := make(map[string]bool)
+	titles := []string{"AC/DC", "AC_DC", "AC:DC", "Paris", "PARIS", "Lyon", "Marseille"}
+	names := make(map[string]string)
+	seen := make(map[string]bool)
+	for _, title := range titles {
+		name := crawlFileName(used, title)
+		low := strings.ToLower(name)
+		if seen[low] {
+			t.Errorf("%s : nom %s déjà pris", title, name)
+		}
+		seen[low] = true
+		names[title] = name
+	}
+	want := map[string]string{
+		"AC/DC":     "AC_DC",
+		"AC_DC":     "AC_DC_" + sha256Hex([]byte("AC_DC"))[:8],
+		"AC:DC":     "AC_DC_" + sha256Hex([]byte("AC:DC"))[:8],
+		"Paris":     "Paris",
+		"PARIS":     "PARIS_" + sha256Hex([]byte("PARIS"))[:8],
+		"Lyon":      "Lyon",
+		"Marseille": "Marseille",
+	}
+	for title, w := range want {
+		if names[title] != w {
+			t.Errorf("crawlFileName(%q) = %q, attendu %q", title, names[title], w)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ------- Wikipédia : liens, catégories, infobox et références --------
+// En plus du texte, les données structurées de l'article sont extraites du contenu (avant le
+// nettoyage fait pour le texte) et enregistrées en JSON dans OutDir : liens internes et externes,
+// catégories, infobox (clé -> valeur) et liste des références.
+
+// Lien vers un autre article
+type wikiLink struct {
+	Title string `json:"title"` // titre normalisé, avec des "_"
+	URL   string `json:"url"`
+	Text  string `json:"text"` // texte du lien dans la page
+}
+
+// Lien vers un autre site
+type wikiExternalLink struct {
+	URL  string `json:"url"`
+	Text string `json:"text"`
+}
+
+// Référence de la liste des notes
+type wikiReference struct {
+	ID   string   `json:"id,omitempty"` // ancre (cite_note-1)
+	Text string   `json:"text"`
+	URLs []string `json:"urls,omitempty"` // liens externes de la référence
+}
+
+// Contenu de wiki_<article>.json
+type wikiData struct {
+	Title         string             `json:"title"`
+	URL           string             `json:"url"`
+	RevID         int64              `json:"revid,omitempty"`
+	Modified      time.Time          `json:"modified,omitzero"`
+	InternalLinks []wikiLink         `json:"internal_links"`
+	ExternalLinks []wikiExternalLink `json:"external_links"`
+	Categories    []string           `json:"categories"`
+	Infobox       map[string]string  `json:"infobox"`
+	References    []wikiReference    `json:"references"`
+}
+
+// Espaces de noms de secours (fr et en), si l'API ne donne pas ceux du wiki :
+// un lien vers Fichier:, Aide:... n'est pas un lien vers un article
+var wikiDefaultNamespaces = map[string]bool{
+	"fichier": true, "file": true, "image": true, "média": true, "media": true,
+	"catégorie": true, "category": true, "modèle": true, "template": true, "module": true,
+	"aide": true, "help": true, "portail": true, "portal": true, "projet": true,
+	"spécial": true, "special": true, "wikipédia": true, "wikipedia": true,
+	"utilisateur": true, "utilisatrice": true, "user": true, "discussion": true, "talk": true,
+	"référence": true, "mediawiki": true,
+}
+
+// Réponse de action=query&meta=siteinfo&siprop=namespaces|namespacealiases (formatversion=2)
+type apiSiteInfo struct {
+	Query struct {
+		Namespaces map[string]struct {
+			ID        int    `json:"id"`
+			Name      string `json:"name"`
+			Canonical string `json:"canonical"`
+		} `json:"namespaces"`
+		NamespaceAliases []struct {
+			ID    int    `json:"id"`
+			Alias string `json:"alias"`
+		} `json:"namespacealiases"`
+	} `json:"query"`
+}
+
+// Espaces de noms d'un wiki, demandés une seule fois même si plusieurs workers en ont besoin en même temps
+type wikiNamespaceSet struct {
+	once sync.Once
+	ns   map[string]bool
+}
+
+// Espaces de noms par adresse d'API, demandés une fois par exécution (les workers de l'exploration les partagent)
+var (
+	wikiNamespacesMu    sync.Mutex // protège seulement la table, pas la requête
+	wikiNamespacesByAPI = make(map[string]*wikiNamespaceSet)
+)
+
+// Nom d'espace de noms comparable au début d'un titre : en minuscules, avec des "_"
+func namespaceKey(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", "_"))
+}
+
+// Espaces de noms du wiki (noms locaux, canoniques et alias) lus par l'API, ceux de secours en cas d'échec
+func wikiNamespaces(cfg Config) map[string]bool {
+	api := wikiAPIURL(cfg)
+	wikiNamespacesMu.Lock()
+	set := wikiNamespacesByAPI[api]
+	if set == nil {
+		set = &wikiNamespaceSet{}
+		wikiNamespacesByAPI[api] = set
+	}
+	wikiNamespacesMu.Unlock()
+
+	// Requête hors du verrou : un wiki lent ne bloque pas ceux des autres adresses
+	set.once.Do(func() { set.ns = fetchWikiNamespaces(cfg, api) })
+	return set.ns
+}
+
+// Demande les espaces de noms à l'API (action=query&meta=siteinfo)
+func fetchWikiNamespaces(cfg Config, api string) map[string]bool {
+	q := url.Values{
+		"action":        {"query"},
+		"format":        {"json"},
+		"formatversion": {"2"},
+		"meta":          {"siteinfo"},
+		"siprop":        {"namespaces|namespacealiases"},
+	}
+	ns := make(map[string]bool)
+	var si apiSiteInfo
+	body, _, err := httpGet(cfg, api+"?"+q.Encode())
+	if err == nil {
+		err = json.Unmarshal(body, &si)
+	}
+	if err == nil {
+		for _, n := range si.Query.Namespaces {
+			if n.ID != 0 { // 0 : les articles
+				for _, name := range []string{n.Name, n.Canonical} {
+					if name != "" {
+						ns[namespaceKey(name)] = true
+					}
+				}
+			}
+		}
+		for _, a := range si.Query.NamespaceAliases {
+			if a.ID != 0 && a.Alias != "" {
+				ns[namespaceKey(a.Alias)] = true
+			}
+		}
+	}
+	if err == nil && len(ns) == 0 {
+		err = fmt.Errorf("réponse sans espace de noms")
+	}
+	if err != nil {
+		fmt.Printf("Espaces de noms du wiki inconnus (%v), liste fr/en utilisée.\n", err)
+		ns = wikiDefaultNamespaces
+	}
+	return ns
+}
+
+// Sélecteur des infobox (infobox_v2 et infobox_v3 sur Wikipédia en français)
+const wikiInfoboxSelector = ".infobox, .infobox_v2, .infobox_v3"
+
+// Titre de l'article visé par un lien de la page, "" si ce n'est pas un lien vers un article
+// du même wiki (autre site, espace de noms, page d'édition...). L'espace de noms est rendu à part.
+func wikiLinkTarget(base, page *url.URL, namespaces map[string]bool, href string) (title, namespace string) {
+	u, err := page.Parse(href)
+	if err != nil || u.Host != base.Host || !strings.HasPrefix(u.EscapedPath(), base.EscapedPath()) {
+		return "", ""
+	}
+	if u.RawQuery != "" {
+		return "", "" // index.php?title=...&action=edit, liens rouges
+	}
+	t, err := normalizeWikiTitle(strings.TrimPrefix(u.EscapedPath(), base.EscapedPath()))
+	if err != nil {
+		return "", ""
+	}
+	if i := strings.Index(t, ":"); i > 0 && namespaces[namespaceKey(t[:i])] {
+		return t, t[:i]
+	}
+	return t, ""
+}
+
+// Texte d'une cellule : les retours à la ligne et les éléments de liste deviennent "; "
+func cellText(s *goquery.Selection) string {
+	c := s.Clone()
+	c.Find("br").ReplaceWithHtml("\n")
+	c.Find("li, p, div").AppendHtml("\n")
+	var parts []string
+	for _, line := range strings.Split(c.Text(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Infobox : une ligne "titre de ligne / valeur" par champ (première infobox de la page)
+func extractInfobox(content *goquery.Selection) map[string]string {
+	box := make(map[string]string)
+	content.Find(wikiInfoboxSelector).First().Find("tr").Each(func(i int, tr *goquery.Selection) {
+		th := tr.ChildrenFiltered("th").First()
+		td := tr.ChildrenFiltered("td").First()
+		if th.Length() == 0 || td.Length() == 0 {
+			return
+		}
+		key, value := blockText(th), cellText(td)
+		if key == "" || value == "" {
+			return
+		}
+		// Une clé répétée garde toutes ses valeurs
+		if prev, ok := box[key]; ok {
+			value = prev + "; " + value
+		}
+		box[key] = value
+	})
+	return box
+}
+
+// Extrait les données structurées de la page
+func extractWikiData(cfg Config, page *wikiPage) wikiData {
+	d := wikiData{
+		Title:         page.Title,
+		URL:           page.URL,
+		RevID:         page.RevID,
+		Modified:      page.Modified,
+		InternalLinks: []wikiLink{},
+		ExternalLinks: []wikiExternalLink{},
+		Categories:    page.Categories,
+		References:    []wikiReference{},
+	}
+	if d.Categories == nil {
+		d.Categories = []string{}
+	}
+
+	content := wikiContent(page.Doc)
+	content.Find("script, style, .mw-editsection").Remove()
+	d.Infobox = extractInfobox(content)
+
+	// Liens (les adresses relatives sont résolues par rapport à la page)
+	base, errBase := url.Parse(wikiBase(cfg))
+	pageURL, errPage := url.Parse(page.URL)
+	namespaces := wikiNamespaces(cfg)
+	seen := make(map[string]bool)
+	content.Find("a[href]").Each(func(i int, a *goquery.Selection) {
+		href, _ := a.Attr("href")
+		if errBase != nil || errPage != nil || strings.HasPrefix(href, "#") ||
+			a.ParentsFiltered(".references, .reflist").Length() > 0 {
+			return // ancres et liens des références (gardés avec chaque référence)
+		}
+		if title, ns := wikiLinkTarget(base, pageURL, namespaces, href); title != "" {
+			if ns == "" && !seen[title] {
+				seen[title] = true
+				d.InternalLinks = append(d.InternalLinks, wikiLink{Title: title, URL: wikiArticleURL(cfg, title), Text: blockText(a)})
+			}
+			return
+		}
+		u, err := pageURL.Parse(href)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == base.Host {
+			return
+		}
+		if !seen[u.String()] {
+			seen[u.String()] = true
+			d.ExternalLinks = append(d.ExternalLinks, wikiExternalLink{URL: u.String(), Text: blockText(a)})
+		}
+	})
+
+	content.Find(".references > li").Each(func(i int, li *goquery.Selection) {
+		r := wikiReference{ID: li.AttrOr("id", "")}
+		text := li.Find(".reference-text").First()
+		if text.Length() == 0 {
+			text = li.Clone()
+			text.Find(".mw-cite-backlink").Remove()
+		}
+		r.Text = blockText(text)
+		li.Find("a.external[href]").Each(func(j int, a *goquery.Selection) {
+			r.URLs = append(r.URLs, a.AttrOr("href", ""))
+		})
+		if r.Text != "" {
+			d.References = append(d.References, r)
+		}
+	})
+	return d
+}
+
+// Écrit les données en JSON (UTF-8, quelle que soit output_encoding)
+func writeWikiData(path string, d wikiData) error {
+	b, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Réponse siteinfo d'un wiki fictif : espaces locaux, canoniques et alias
+const siteInfoJSON = `{"batchcomplete": true, "query": {
+	"namespaces": {
+		"-2": {"id": -2, "name": "Média", "canonical": "Media"},
+		"0": {"id": 0, "name": ""},
+		"6": {"id": 6, "name": "Fichier", "canonical": "File"},
+		"14": {"id": 14, "name": "Catégorie", "canonical": "Category"},
+		"104": {"id": 104, "name": "Sujet de discussion"}
+	},
+	"namespacealiases": [{"id": 6, "alias": "Image"}, {"id": 0, "alias": "Article"}]
+}}`
+
+// Serveur d'API qui répond body et compte les requêtes
+func siteInfoServer(t *testing.T, code int, body string) (Config, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Query().Get("meta") != "siteinfo" {
+			t.Errorf("requête inattendue : %s", r.URL)
+		}
+		w.WriteHeader(code)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return Config{WikiAPIURL: srv.URL + "/w/api.php"}, &hits
+}
+
+func TestWikiNamespaces(t *testing.T) {
+	cfg, hits := siteInfoServer(t, http.StatusOK, siteInfoJSON)
+
+	// Plusieurs workers en même temps : une seule requête (go test -race)
+	var wg sync.WaitGroup
+	results := make([]map[string]bool, 8)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = wikiNamespaces(cfg)
+		}()
+	}
+	wg.Wait()
+	if hits.Load() != 1 {
+		t.Errorf("%d requête(s), attendu 1", hits.Load())
+	}
+
+	var keys []string
+	for k := range results[0] {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if got, want := strings.Join(keys, "|"), "category|catégorie|fichier|file|image|media|média|sujet_de_discussion"; got != want {
+		t.Errorf("espaces de noms %q, attendu %q", got, want)
+	}
+
+	// Les liens vers ces espaces de noms ne sont pas des articles
+	base, _ := url.Parse("https://fr.wikipedia.org/wiki/")
+	page, _ := url.Parse("https://fr.wikipedia.org/wiki/Paris")
+	for href, want := range map[string]string{
+		"/wiki/Image:Paris.jpg":           "Image",
+		"/wiki/Sujet_de_discussion:Paris": "Sujet_de_discussion",
+		"/wiki/Cat%C3%A9gorie:Ville":      "Catégorie",
+		"/wiki/Paris:_ville_et_capitale":  "",
+		"/wiki/Mod%C3%A8le:Infobox_Ville": "", // espace de noms absent de ce wiki
+	} {
+		if _, ns := wikiLinkTarget(base, page, results[0], href); ns != want {
+			t.Errorf("%s : espace de noms %q, attendu %q", href, ns, want)
+		}
+	}
+}
+
+// API en erreur ou réponse inutilisable : liste fr/en de secours
+func TestWikiNamespacesFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		code int
+		body string
+	}{
+		{"404", http.StatusNotFound, ""},
+		{"JSON invalide", http.StatusOK, "<html>pas du JSON</html>"},
+		{"aucun espace de noms", http.StatusOK, `{"query": {"namespaces": {"0": {"id": 0, "name": ""}}}}`},
+	}
+	for _, tt := range tests {
+		cfg, hits := siteInfoServer(t, tt.code, tt.body)
+		ns := wikiNamespaces(cfg)
+		if len(ns) != len(wikiDefaultNamespaces) || !ns["fichier"] || !ns["template"] {
+			t.Errorf("%s : %d espace(s) de noms, attendu la liste de secours", tt.name, len(ns))
+		}
+		// L'échec est retenu : pas de nouvelle requête pour cette API
+		wikiNamespaces(cfg)
+		if hits.Load() != 1 {
+			t.Errorf("%s : %d requête(s), attendu 1", tt.name, hits.Load())
+		}
+	}
+}
+
+// Un wiki qui tarde à répondre ne bloque pas les autres
+func TestWikiNamespacesNotBlocking(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(siteInfoJSON))
+	}))
+	t.Cleanup(slow.Close)
+	t.Cleanup(func() { close(release) })
+	go wikiNamespaces(Config{WikiAPIURL: slow.URL + "/w/api.php"})
+	time.Sleep(50 * time.Millisecond) // requête au serveur lent en cours
+
+	cfg, _ := siteInfoServer(t, http.StatusOK, siteInfoJSON)
+	done := make(chan struct{})
+	go func() {
+		wikiNamespaces(cfg)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("espaces de noms bloqués par la requête d'un autre wiki")
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ------- Wikipédia : extraction du texte --------
+// Seul le contenu de l'article est lu (pas les menus ni le pied de page), sans les appels
+// de note [1], les liens "modifier" ni les éléments cachés. Les titres, listes et légendes
+// de tableaux peuvent être ajoutés aux paragraphes.
+
+// Éléments ajoutés aux paragraphes (clés wiki_headings, wiki_lists, wiki_captions)
+type wikiExtractOptions struct {
+	Headings bool
+	Lists    bool
+	Captions bool
+}
+
+func wikiOptions(cfg Config) wikiExtractOptions {
+	return wikiExtractOptions{Headings: cfg.WikiHeadings, Lists: cfg.WikiLists, Captions: cfg.WikiCaptions}
+}
+
+// Bloc de texte extrait, dans l'ordre de la page
+type wikiBlock struct {
+	Kind    string // "p", "h" (titre), "li" (élément de liste) ou "caption" (légende de tableau)
+	Level   int    // niveau du titre : 2 pour h2, 3 pour h3...
+	Text    string
+	Section string // section du bloc : "Conception > Nom", "" pour l'introduction
+}
+
+// Nom affiché de l'introduction (texte avant le premier titre)
+const wikiIntroSection = "Introduction"
+
+// Section affichée
+func sectionName(s string) string {
+	if s == "" {
+		return wikiIntroSection
+	}
+	return s
+}
+
+// Les titres sont toujours extraits (pour les sections) mais ne sont écrits que si wiki_headings est actif
+func (b wikiBlock) shown(opts wikiExtractOptions) bool {
+	return b.Kind != "h" || opts.Headings
+}
+
+// Rendu dans le fichier texte : == Titre ==, - élément, [Légende]
+func (b wikiBlock) String() string {
+	switch b.Kind {
+	case "h":
+		mark := strings.Repeat("=", b.Level)
+		return mark + " " + b.Text + " " + mark
+	case "li":
+		return "- " + b.Text
+	case "caption":
+		return "[" + b.Text + "]"
+	}
+	return b.Text
+}
+
+// Conteneurs possibles du contenu de l'article, du plus précis au plus large
+var wikiContentSelectors = []string{
+	"#mw-content-text > .mw-parser-output",
+	".mw-parser-output",
+	"#mw-content-text",
+	"#bodyContent",
+	"main",
+	"body",
+}
+
+// Éléments retirés avant l'extraction
+const wikiNoiseSelector = "script, style, sup.reference, sup.mw-ref, .mw-editsection, .mw-empty-elt, " +
+	"[hidden], [style*='display:none'], [style*='display: none']"
+
+// Listes qui ne font pas partie du texte de l'article (notes, palettes de navigation)
+const wikiSkipListSelector = ".references, .reflist, .navbox"
+
+// Contenu de l'article (copie, le document n'est pas modifié)
+func wikiContent(doc *goquery.Document) *goquery.Selection {
+	for _, sel := range wikiContentSelectors {
+		if s := doc.Find(sel).First(); s.Length() > 0 {
+			return s.Clone()
+		}
+	}
+	return doc.Selection.Clone()
+}
+
+// Texte d'un élément, espaces réduits
+func blockText(s *goquery.Selection) string {
+	return strings.Join(strings.Fields(s.Text()), " ")
+}
+
+// Extrait les blocs de texte de l'article, chacun avec sa section
+func extractWikiBlocks(doc *goquery.Document, opts wikiExtractOptions) []wikiBlock {
+	return extractBlocks(wikiContent(doc), opts)
+}
+
+// Extrait les blocs de texte d'un contenu (modifié : les éléments parasites sont retirés)
+func extractBlocks(content *goquery.Selection, opts wikiExtractOptions) []wikiBlock {
+	content.Find(wikiNoiseSelector).Remove()
+
+	sel := "p, h2, h3, h4, h5, h6"
+	if opts.Lists {
+		sel += ", li"
+	}
+	if opts.Captions {
+		sel += ", caption"
+	}
+
+	var blocks []wikiBlock
+	var heads []wikiBlock // titres englobants, du h2 au titre courant
+	content.Find(sel).Each(func(i int, s *goquery.Selection) {
+		b := wikiBlock{Kind: "p"}
+		switch name := goquery.NodeName(s); name {
+		case "p":
+			// Un paragraphe dans un élément de liste est déjà dans le texte de l'élément
+			if opts.Lists && s.ParentsFiltered("li").Length() > 0 {
+				return
+			}
+			b.Text = blockText(s)
+		case "li":
+			if s.ParentsFiltered(wikiSkipListSelector).Length() > 0 {
+				return
+			}
+			// Sans les sous-listes, qui donnent leurs propres éléments
+			b.Kind = "li"
+			b.Text = blockText(s.Clone().Find("ul, ol").Remove().End())
+		case "caption":
+			b.Kind = "caption"
+			b.Text = blockText(s)
+		default:
+			b.Kind = "h"
+			b.Level = int(name[1] - '0')
+			b.Text = blockText(s)
+			if b.Text == "" {
+				return
+			}
+			// Un titre ferme les sections de niveau égal ou inférieur
+			for len(heads) > 0 && heads[len(heads)-1].Level >= b.Level {
+				heads = heads[:len(heads)-1]
+			}
+			heads = append(heads, b)
+		}
+		if b.Text == "" {
+			return
+		}
+		names := make([]string, len(heads))
+		for i, h := range heads {
+			names[i] = h.Text
+		}
+		b.Section = strings.Join(names, " > ")
+		blocks = append(blocks, b)
+	})
+	return blocks
+}
+
+// Sections de la page, dans l'ordre
+func wikiSections(blocks []wikiBlock) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, b := range blocks {
+		if !seen[b.Section] {
+			seen[b.Section] = true
+			names = append(names, b.Section)
+		}
+	}
+	return names
+}
+
+// Garde les blocs d'une section et de ses sous-sections ; le nom peut être celui d'une section
+// à n'importe quel niveau ("Nom" garde "Conception > Nom"), sans tenir compte de la casse
+func filterWikiSection(blocks []wikiBlock, name string) []wikiBlock {
+	name = strings.TrimSpace(name)
+	var kept []wikiBlock
+	for _, b := range blocks {
+		if b.Section == "" {
+			if strings.EqualFold(name, wikiIntroSection) {
+				kept = append(kept, b)
+			}
+			continue
+		}
+		for _, part := range strings.Split(b.Section, " > ") {
+			if strings.EqualFold(part, name) {
+				kept = append(kept, b)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// Table "sections" : blocs et mots de chaque section (titres non comptés)
+func wikiSectionsTable(blocks []wikiBlock) reportTable {
+	t := reportTable{Name: "sections", Columns: []string{"section", "level", "blocks", "words", "avg_word_len"}}
+	type stats struct{ level, blocks, words, wordLen int }
+	byName := make(map[string]*stats)
+	for _, b := range blocks {
+		st := byName[b.Section]
+		if st == nil {
+			st = &stats{level: 1}
+			byName[b.Section] = st
+		}
+		if b.Kind == "h" {
+			st.level = b.Level
+			continue
+		}
+		w, l := countWords(b.Text)
+		st.blocks++
+		st.words += w
+		st.wordLen += l
+	}
+	for _, name := range wikiSections(blocks) {
+		st := byName[name]
+		avg := 0.0
+		if st.words > 0 {
+			avg = float64(st.wordLen) / float64(st.words)
+		}
+		t.Rows = append(t.Rows, []any{sectionName(name), st.level, st.blocks, st.words, avg})
+	}
+	return t
+}
+
+// Échappe un début de ligne qui serait lu comme un titre, une liste ou une citation Markdown
+func escapeMarkdownLine(s string) string {
+	if strings.HasPrefix(s, "#") || strings.HasPrefix(s, ">") || strings.HasPrefix(s, "- ") ||
+		strings.HasPrefix(s, "* ") || strings.HasPrefix(s, "+ ") {
+		return "\\" + s
+	}
+	if i := strings.Index(s, ". "); i > 0 && strings.Trim(s[:i], "0123456789") == "" {
+		return s[:i] + "\\" + s[i:]
+	}
+	return s
+}
+
+// Export Markdown : titre de l'article, titres des sections (##, ###...), paragraphes, listes et légendes
+func wikiMarkdown(title, source string, blocks []wikiBlock) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	if source != "" {
+		fmt.Fprintf(&sb, "Source : %s\n\n", source)
+	}
+	for i, b := range blocks {
+		switch b.Kind {
+		case "h":
+			fmt.Fprintf(&sb, "%s %s\n\n", strings.Repeat("#", b.Level), b.Text)
+		case "li":
+			sb.WriteString("- " + b.Text + "\n")
+			// Ligne vide après le dernier élément de la liste
+			if i+1 == len(blocks) || blocks[i+1].Kind != "li" {
+				sb.WriteString("\n")
+			}
+		case "caption":
+			fmt.Fprintf(&sb, "*%s*\n\n", b.Text)
+		default:
+			sb.WriteString(escapeMarkdownLine(b.Text) + "\n\n")
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}